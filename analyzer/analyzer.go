@@ -0,0 +1,222 @@
+// Package analyzer turns the declaration-walking logic that symbols
+// uses internally into a reusable framework. An Analyzer parses a
+// directory of Go source and, for every struct, function, method,
+// closure, go statement, and defer statement it finds, calls back into
+// each registered Visitor. One Visitor might build a JSON symbol
+// table, another a call graph of higher-order calls, another a linter
+// flagging naked returns - all from the same walk.
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"sync"
+)
+
+// ParserFunc parses the Go source in dir and returns its files. The
+// default, used unless overridden with WithParser, is go/parser over
+// every .go file in dir.
+type ParserFunc func(dir string) ([]*ast.File, *token.FileSet, error)
+
+// Analyzer walks a package's declarations and dispatches callbacks to
+// its registered Visitors. Build one with New and the With* options.
+type Analyzer struct {
+	parse       ParserFunc
+	visitors    []Visitor
+	concurrency int
+	deferHook   func(CallInfo)
+}
+
+// Option configures an Analyzer constructed with New.
+type Option func(*Analyzer)
+
+// WithParser overrides how source files are parsed, for example to
+// analyze an already-parsed in-memory AST instead of reading a
+// directory from disk.
+func WithParser(p ParserFunc) Option {
+	return func(a *Analyzer) { a.parse = p }
+}
+
+// WithVisitor registers a Visitor to receive callbacks during Analyze.
+// Visitors run in registration order and are never called concurrently
+// with one another, even when WithConcurrency is greater than one.
+func WithVisitor(v Visitor) Option {
+	return func(a *Analyzer) { a.visitors = append(a.visitors, v) }
+}
+
+// WithConcurrency sets how many files Analyze may parse and walk
+// concurrently before dispatching their callbacks. n <= 0 is treated
+// as 1.
+func WithConcurrency(n int) Option {
+	return func(a *Analyzer) { a.concurrency = n }
+}
+
+// WithDeferHook registers a shorthand callback for defer statements,
+// for callers that only care about defers and don't want to implement
+// a full Visitor.
+func WithDeferHook(fn func(CallInfo)) Option {
+	return func(a *Analyzer) { a.deferHook = fn }
+}
+
+// New builds an Analyzer from the given options.
+func New(opts ...Option) *Analyzer {
+	a := &Analyzer{parse: defaultParser, concurrency: 1}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.concurrency <= 0 {
+		a.concurrency = 1
+	}
+	return a
+}
+
+// Analyze parses dir and walks its declarations, dispatching callbacks
+// to every registered Visitor and, for defer statements, the
+// WithDeferHook callback.
+func (a *Analyzer) Analyze(dir string) error {
+	files, _, err := a.parse(dir)
+	if err != nil {
+		return fmt.Errorf("analyzer: %w", err)
+	}
+	return a.AnalyzeFiles(files)
+}
+
+// AnalyzeFiles walks already-parsed files and dispatches callbacks the
+// same way Analyze does. It's the lower-level entry point for callers
+// that parse files themselves - for example a worker pool parsing
+// files independently via go/parser.ParseFile, one at a time, the way
+// the pipeline package does.
+func (a *Analyzer) AnalyzeFiles(files []*ast.File) error {
+	raw := collectStructTypes(files)
+
+	type walked struct {
+		structs  []StructInfo
+		funcs    []FuncInfo
+		methods  []FuncInfo
+		closures []ClosureInfo
+		goStmts  []CallInfo
+		defers   []CallInfo
+	}
+	results := make([]walked, len(files))
+
+	sem := make(chan struct{}, a.concurrency)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		i, file := i, file
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			w := walked{}
+			for _, decl := range file.Decls {
+				switch d := decl.(type) {
+				case *ast.GenDecl:
+					for _, si := range structsIn(d, raw) {
+						w.structs = append(w.structs, si)
+					}
+				case *ast.FuncDecl:
+					fi := funcInfo(d)
+					if fi.Receiver != nil {
+						w.methods = append(w.methods, fi)
+					} else {
+						w.funcs = append(w.funcs, fi)
+					}
+					w.closures = append(w.closures, closuresIn(d)...)
+					w.goStmts = append(w.goStmts, goStmtsIn(d)...)
+					w.defers = append(w.defers, deferStmtsIn(d)...)
+				}
+			}
+			results[i] = w
+		}()
+	}
+	wg.Wait()
+
+	for _, w := range results {
+		for _, si := range w.structs {
+			a.dispatchStruct(si)
+		}
+		for _, fi := range w.funcs {
+			a.dispatchFunc(fi)
+		}
+		for _, fi := range w.methods {
+			a.dispatchMethod(fi)
+		}
+		for _, ci := range w.closures {
+			a.dispatchClosure(ci)
+		}
+		for _, ci := range w.goStmts {
+			a.dispatchGoStmt(ci)
+		}
+		for _, ci := range w.defers {
+			a.dispatchDefer(ci)
+		}
+	}
+	return nil
+}
+
+func (a *Analyzer) dispatchStruct(s StructInfo) {
+	for _, v := range a.visitors {
+		v.VisitStruct(s)
+	}
+}
+
+func (a *Analyzer) dispatchFunc(f FuncInfo) {
+	for _, v := range a.visitors {
+		v.VisitFunc(f)
+	}
+}
+
+func (a *Analyzer) dispatchMethod(f FuncInfo) {
+	for _, v := range a.visitors {
+		v.VisitMethod(f)
+	}
+}
+
+func (a *Analyzer) dispatchClosure(c ClosureInfo) {
+	for _, v := range a.visitors {
+		v.VisitClosure(c)
+	}
+}
+
+func (a *Analyzer) dispatchGoStmt(c CallInfo) {
+	for _, v := range a.visitors {
+		v.VisitGoStmt(c)
+	}
+}
+
+func (a *Analyzer) dispatchDefer(c CallInfo) {
+	for _, v := range a.visitors {
+		v.VisitDefer(c)
+	}
+	if a.deferHook != nil {
+		a.deferHook(c)
+	}
+}
+
+// defaultParser parses every Go file in dir with go/parser.
+func defaultParser(dir string) ([]*ast.File, *token.FileSet, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var names []string
+	byName := map[string]*ast.File{}
+	for _, pkg := range pkgs {
+		for name, file := range pkg.Files {
+			names = append(names, name)
+			byName[name] = file
+		}
+	}
+	sort.Strings(names)
+
+	files := make([]*ast.File, len(names))
+	for i, name := range names {
+		files[i] = byName[name]
+	}
+	return files, fset, nil
+}
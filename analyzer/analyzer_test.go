@@ -0,0 +1,123 @@
+package analyzer
+
+import (
+	"sort"
+	"sync"
+	"testing"
+)
+
+const corpusDir = "../testdata/corpus/go"
+
+// recordingVisitor records the name/callee of everything it sees, so
+// tests can assert on the walk without caring about dispatch order.
+type recordingVisitor struct {
+	BaseVisitor
+
+	mu       sync.Mutex
+	structs  []string
+	funcs    []string
+	methods  []string
+	closures []string
+	goStmts  []string
+	defers   []string
+}
+
+func (v *recordingVisitor) VisitStruct(s StructInfo) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.structs = append(v.structs, s.Name)
+}
+
+func (v *recordingVisitor) VisitFunc(f FuncInfo) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.funcs = append(v.funcs, f.Name)
+}
+
+func (v *recordingVisitor) VisitMethod(f FuncInfo) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.methods = append(v.methods, f.Name)
+}
+
+func (v *recordingVisitor) VisitClosure(c ClosureInfo) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.closures = append(v.closures, c.EnclosingFunc)
+}
+
+func (v *recordingVisitor) VisitGoStmt(c CallInfo) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.goStmts = append(v.goStmts, c.Callee)
+}
+
+func (v *recordingVisitor) VisitDefer(c CallInfo) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.defers = append(v.defers, c.Callee)
+}
+
+func contains(items []string, want string) bool {
+	for _, s := range items {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAnalyzeDispatchesEveryKind(t *testing.T) {
+	v := &recordingVisitor{}
+	a := New(WithVisitor(v))
+	if err := a.Analyze(corpusDir); err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	if !contains(v.structs, "Employee") {
+		t.Errorf("structs = %v, want Employee present", v.structs)
+	}
+	if !contains(v.funcs, "Add") {
+		t.Errorf("funcs = %v, want Add present", v.funcs)
+	}
+	if !contains(v.methods, "Increment") {
+		t.Errorf("methods = %v, want Increment present", v.methods)
+	}
+	if !contains(v.closures, "OuterFunction") {
+		t.Errorf("closures = %v, want OuterFunction present", v.closures)
+	}
+	if !contains(v.goStmts, "AsyncWork") {
+		t.Errorf("goStmts = %v, want AsyncWork present", v.goStmts)
+	}
+	if !contains(v.defers, "fmt.Println") {
+		t.Errorf("defers = %v, want fmt.Println present", v.defers)
+	}
+}
+
+func TestWithDeferHookRunsAlongsideVisitors(t *testing.T) {
+	var mu sync.Mutex
+	var hooked []string
+	a := New(WithDeferHook(func(c CallInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		hooked = append(hooked, c.Callee)
+	}))
+	if err := a.Analyze(corpusDir); err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	sort.Strings(hooked)
+	if !contains(hooked, "fmt.Println") {
+		t.Errorf("hooked defers = %v, want fmt.Println present", hooked)
+	}
+}
+
+func TestWithConcurrencyStillVisitsEveryFile(t *testing.T) {
+	v := &recordingVisitor{}
+	a := New(WithVisitor(v), WithConcurrency(4))
+	if err := a.Analyze(corpusDir); err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if !contains(v.structs, "Employee") || !contains(v.funcs, "Add") {
+		t.Errorf("concurrent analyze missed declarations: structs=%v funcs=%v", v.structs, v.funcs)
+	}
+}
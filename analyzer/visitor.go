@@ -0,0 +1,62 @@
+package analyzer
+
+import "github.com/therecluse26/reflex/symbols"
+
+// StructInfo describes a struct declaration passed to Visitor.VisitStruct.
+type StructInfo struct {
+	Name     string
+	Fields   []symbols.Field
+	Promoted []symbols.Field
+}
+
+// FuncInfo describes a function or method declaration passed to
+// Visitor.VisitFunc or Visitor.VisitMethod. Receiver is nil for
+// VisitFunc calls.
+type FuncInfo struct {
+	Name     string
+	Receiver *symbols.Receiver
+	Params   []symbols.Field
+	Results  []symbols.Field
+	Variadic bool
+}
+
+// ClosureInfo describes a function literal found inside a function
+// body, passed to Visitor.VisitClosure.
+type ClosureInfo struct {
+	EnclosingFunc string
+}
+
+// CallInfo describes a call expression found in a go or defer
+// statement, passed to Visitor.VisitGoStmt, Visitor.VisitDefer, and
+// any WithDeferHook callback.
+type CallInfo struct {
+	EnclosingFunc string
+	Callee        string
+}
+
+// Visitor receives callbacks as an Analyzer walks a package's
+// declarations. Implementations should embed BaseVisitor so they only
+// need to define the callbacks they care about.
+type Visitor interface {
+	VisitStruct(StructInfo)
+	VisitFunc(FuncInfo)
+	VisitMethod(FuncInfo)
+	VisitClosure(ClosureInfo)
+	VisitGoStmt(CallInfo)
+	VisitDefer(CallInfo)
+}
+
+// BaseVisitor implements Visitor with no-op methods. Embed it in a
+// Visitor implementation to pick up defaults for callbacks you don't
+// need, e.g.:
+//
+//	type callGraphVisitor struct{ analyzer.BaseVisitor }
+//	func (v *callGraphVisitor) VisitGoStmt(c analyzer.CallInfo) { ... }
+type BaseVisitor struct{}
+
+func (BaseVisitor) VisitStruct(StructInfo)   {}
+func (BaseVisitor) VisitFunc(FuncInfo)       {}
+func (BaseVisitor) VisitMethod(FuncInfo)     {}
+func (BaseVisitor) VisitClosure(ClosureInfo) {}
+func (BaseVisitor) VisitGoStmt(CallInfo)     {}
+func (BaseVisitor) VisitDefer(CallInfo)      {}
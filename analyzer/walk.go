@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/therecluse26/reflex/internal/astutil"
+	"github.com/therecluse26/reflex/symbols"
+)
+
+// collectStructTypes indexes every top-level struct declaration across
+// files by name, so embedded fields can be resolved regardless of
+// which file declares the embedded type.
+func collectStructTypes(files []*ast.File) map[string]*ast.StructType {
+	raw := map[string]*ast.StructType{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					raw[ts.Name.Name] = st
+				}
+			}
+		}
+	}
+	return raw
+}
+
+func structsIn(gen *ast.GenDecl, raw map[string]*ast.StructType) []StructInfo {
+	if gen.Tok != token.TYPE {
+		return nil
+	}
+	var out []StructInfo
+	for _, spec := range gen.Specs {
+		ts, ok := spec.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			continue
+		}
+		fields, promoted := astutil.FlattenFields(ts.Name.Name, st, raw)
+		out = append(out, StructInfo{Name: ts.Name.Name, Fields: toSymbolFields(fields), Promoted: toSymbolFields(promoted)})
+	}
+	return out
+}
+
+func toSymbolFields(fields []astutil.Field) []symbols.Field {
+	if fields == nil {
+		return nil
+	}
+	out := make([]symbols.Field, len(fields))
+	for i, f := range fields {
+		out[i] = symbols.Field{Name: f.Name, Type: f.Type, Tags: f.Tags}
+	}
+	return out
+}
+
+func funcInfo(fd *ast.FuncDecl) FuncInfo {
+	fi := FuncInfo{Name: fd.Name.Name}
+
+	if fd.Recv != nil && len(fd.Recv.List) == 1 {
+		recv := fd.Recv.List[0]
+		name := ""
+		if len(recv.Names) > 0 {
+			name = recv.Names[0].Name
+		}
+		typ := astutil.TypeString(recv.Type)
+		fi.Receiver = &symbols.Receiver{
+			Name:    name,
+			Type:    strings.TrimPrefix(typ, "*"),
+			Pointer: strings.HasPrefix(typ, "*"),
+		}
+	}
+
+	params := fd.Type.Params.List
+	for i, p := range params {
+		typ := astutil.TypeString(p.Type)
+		if i == len(params)-1 {
+			if _, ok := p.Type.(*ast.Ellipsis); ok {
+				fi.Variadic = true
+			}
+		}
+		if len(p.Names) == 0 {
+			fi.Params = append(fi.Params, symbols.Field{Type: typ})
+			continue
+		}
+		for _, n := range p.Names {
+			fi.Params = append(fi.Params, symbols.Field{Name: n.Name, Type: typ})
+		}
+	}
+
+	if fd.Type.Results != nil {
+		for _, r := range fd.Type.Results.List {
+			typ := astutil.TypeString(r.Type)
+			if len(r.Names) == 0 {
+				fi.Results = append(fi.Results, symbols.Field{Type: typ})
+				continue
+			}
+			for _, n := range r.Names {
+				fi.Results = append(fi.Results, symbols.Field{Name: n.Name, Type: typ})
+			}
+		}
+	}
+
+	return fi
+}
+
+func closuresIn(fd *ast.FuncDecl) []ClosureInfo {
+	if fd.Body == nil {
+		return nil
+	}
+	var out []ClosureInfo
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			out = append(out, ClosureInfo{EnclosingFunc: fd.Name.Name})
+		}
+		return true
+	})
+	return out
+}
+
+func goStmtsIn(fd *ast.FuncDecl) []CallInfo {
+	if fd.Body == nil {
+		return nil
+	}
+	var out []CallInfo
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if g, ok := n.(*ast.GoStmt); ok {
+			out = append(out, CallInfo{EnclosingFunc: fd.Name.Name, Callee: calleeString(g.Call.Fun)})
+		}
+		return true
+	})
+	return out
+}
+
+func deferStmtsIn(fd *ast.FuncDecl) []CallInfo {
+	if fd.Body == nil {
+		return nil
+	}
+	var out []CallInfo
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if d, ok := n.(*ast.DeferStmt); ok {
+			out = append(out, CallInfo{EnclosingFunc: fd.Name.Name, Callee: calleeString(d.Call.Fun)})
+		}
+		return true
+	})
+	return out
+}
+
+// calleeString renders a call's callee expression as a readable name,
+// e.g. "AsyncWork" or "fmt.Println".
+func calleeString(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		return calleeString(f.X) + "." + f.Sel.Name
+	default:
+		return astutil.TypeString(fun)
+	}
+}
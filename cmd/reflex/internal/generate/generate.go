@@ -0,0 +1,44 @@
+// Package generate implements the "reflex generate" subcommand.
+package generate
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/therecluse26/reflex/codegen"
+)
+
+// Run parses args as "reflex generate" flags and writes the generated
+// companion file alongside the source package.
+func Run(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing the Go package to generate from")
+	pkgName := fs.String("pkg", "", "package name for the generated file (defaults to the directory name)")
+	out := fs.String("out", "reflex_generated.go", "output file name, written inside -dir")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	name := *pkgName
+	if name == "" {
+		abs, err := filepath.Abs(*dir)
+		if err != nil {
+			return err
+		}
+		name = filepath.Base(abs)
+	}
+
+	g := codegen.New(*dir, name)
+	src, err := g.Generate()
+	if err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+
+	outPath := filepath.Join(*dir, *out)
+	if err := os.WriteFile(outPath, src, 0o644); err != nil {
+		return fmt.Errorf("generate: write %s: %w", outPath, err)
+	}
+	return nil
+}
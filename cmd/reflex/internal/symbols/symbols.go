@@ -0,0 +1,42 @@
+// Package symbols implements the "reflex symbols" subcommand.
+package symbols
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	symbolpkg "github.com/therecluse26/reflex/symbols"
+)
+
+// Run parses args as "reflex symbols" flags, extracts symbols from the
+// package at -dir, narrows them with -filter if given, and writes the
+// result to stdout as JSON.
+func Run(args []string) error {
+	fs := flag.NewFlagSet("symbols", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing the Go package to extract symbols from")
+	filter := fs.String("filter", "", `narrow results with a "key:value" query, e.g. "kind:struct tag:json"`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	syms, err := symbolpkg.Extract(*dir)
+	if err != nil {
+		return fmt.Errorf("symbols: %w", err)
+	}
+
+	if *filter != "" {
+		syms, err = symbolpkg.Filter(syms, *filter)
+		if err != nil {
+			return fmt.Errorf("symbols: %w", err)
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(syms); err != nil {
+		return fmt.Errorf("symbols: encode: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Command reflex is the CLI entry point for the reflex toolchain.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/therecluse26/reflex/cmd/reflex/internal/generate"
+	"github.com/therecluse26/reflex/cmd/reflex/internal/symbols"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "generate":
+		err = generate.Run(os.Args[2:])
+	case "symbols":
+		err = symbols.Run(os.Args[2:])
+	case "-h", "--help", "help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "reflex: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reflex:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: reflex <command> [flags]
+
+Commands:
+  generate   Generate tag-driven companion source for a package
+  symbols    Print a package's extracted symbols as JSON, optionally filtered
+  help       Show this message`)
+}
@@ -0,0 +1,84 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const corpusDir = "../testdata/corpus/go"
+
+func TestLoadFlattensPromotedFields(t *testing.T) {
+	structs, err := Load(corpusDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var employee *Struct
+	for i := range structs {
+		if structs[i].Name == "Employee" {
+			employee = &structs[i]
+		}
+	}
+	if employee == nil {
+		t.Fatal("Employee struct not found")
+	}
+
+	if len(employee.Promoted) != 3 {
+		t.Fatalf("len(Promoted) = %d, want 3 (Name, Age, Email promoted from Person)", len(employee.Promoted))
+	}
+	if employee.Promoted[0].Name != "Name" {
+		t.Errorf("Promoted[0].Name = %q, want Name", employee.Promoted[0].Name)
+	}
+}
+
+func TestGenerateEmitsTaggedHelpers(t *testing.T) {
+	g := New(corpusDir, "corpus")
+	src, err := g.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	out := string(src)
+	for _, want := range []string{
+		"func (v *User) ToMap()",
+		"func (v *User) FromMap(",
+		"func SelectUserSQL() string",
+		"func InsertUserSQL() string",
+		"func ScanUser(",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated source missing %q\n---\n%s", want, out)
+		}
+	}
+}
+
+func TestLoadRejectsTypeErrors(t *testing.T) {
+	dir := t.TempDir()
+	src := `package bad
+
+func BadTypes() int {
+	var x string = 5
+	return x
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "bad.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := Load(dir); err == nil {
+		t.Fatal("Load() error = nil, want a type-check error")
+	}
+}
+
+func TestGenerateSkipsStructsWithoutTags(t *testing.T) {
+	g := New(corpusDir, "corpus")
+	src, err := g.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(src), "Point) ToMap") {
+		t.Error("Point has no json tags and should not get a ToMap helper")
+	}
+}
@@ -0,0 +1,15 @@
+package codegen
+
+// Emitter generates companion source for one struct-tag namespace,
+// such as "json" or "db". Registering a new Emitter is the supported
+// way to teach the generator about a tag namespace it doesn't already
+// know about.
+type Emitter interface {
+	// Namespace is the struct-tag key this emitter reads, e.g. "json".
+	Namespace() string
+
+	// Emit returns the Go source to append for the given struct, or
+	// nil if the struct has nothing for this emitter to generate
+	// (for example, none of its fields carry the emitter's tag).
+	Emit(s Struct) ([]byte, error)
+}
@@ -0,0 +1,71 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+)
+
+// Generator loads structs from a directory and runs them through a set
+// of registered Emitters to produce one companion Go file per package.
+type Generator struct {
+	dir      string
+	pkgName  string
+	emitters []Emitter
+}
+
+// New creates a Generator for the Go files in dir, attributing the
+// generated file to pkgName. It comes pre-registered with the built-in
+// marshal, SQL, and validate emitters; call Register to add more.
+func New(dir, pkgName string) *Generator {
+	g := &Generator{dir: dir, pkgName: pkgName}
+	g.Register(MarshalEmitter{})
+	g.Register(SQLEmitter{})
+	g.Register(ValidateEmitter{})
+	return g
+}
+
+// Register adds an Emitter to the generator. Registering a second
+// Emitter for the same namespace replaces the first.
+func (g *Generator) Register(e Emitter) {
+	for i, existing := range g.emitters {
+		if existing.Namespace() == e.Namespace() {
+			g.emitters[i] = e
+			return
+		}
+	}
+	g.emitters = append(g.emitters, e)
+}
+
+// Generate loads the structs in g.dir and returns the formatted,
+// gofmt-ready source of the companion file.
+func (g *Generator) Generate() ([]byte, error) {
+	structs, err := Load(g.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by reflex generate. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkgName)
+
+	for _, s := range structs {
+		for _, e := range g.emitters {
+			src, err := e.Emit(s)
+			if err != nil {
+				return nil, fmt.Errorf("codegen: %s emitter on %s: %w", e.Namespace(), s.Name, err)
+			}
+			if len(src) == 0 {
+				continue
+			}
+			buf.Write(src)
+			buf.WriteByte('\n')
+		}
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: formatting output: %w", err)
+	}
+	return formatted, nil
+}
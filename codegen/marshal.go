@@ -0,0 +1,62 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MarshalEmitter emits ToMap/FromMap helpers keyed off each field's
+// "json" tag, so callers get tag-aware map conversion without paying
+// for a reflect.Marshal round trip.
+type MarshalEmitter struct{}
+
+// Namespace implements Emitter.
+func (MarshalEmitter) Namespace() string { return "json" }
+
+// Emit implements Emitter.
+func (MarshalEmitter) Emit(s Struct) ([]byte, error) {
+	fields := taggedFields(s, "json")
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	out := fmt.Sprintf("// ToMap returns %s as a map keyed by its json tags.\n", s.Name)
+	out += fmt.Sprintf("func (v *%s) ToMap() map[string]interface{} {\n", s.Name)
+	out += "\tm := make(map[string]interface{})\n"
+	for _, f := range fields {
+		out += fmt.Sprintf("\tm[%q] = v.%s\n", jsonName(f), f.Name)
+	}
+	out += "\treturn m\n}\n\n"
+
+	out += fmt.Sprintf("// FromMap populates %s from a map keyed by its json tags.\n", s.Name)
+	out += fmt.Sprintf("func (v *%s) FromMap(m map[string]interface{}) {\n", s.Name)
+	for _, f := range fields {
+		out += fmt.Sprintf("\tif val, ok := m[%q]; ok {\n", jsonName(f))
+		out += fmt.Sprintf("\t\tif typed, ok := val.(%s); ok {\n", f.Type)
+		out += fmt.Sprintf("\t\t\tv.%s = typed\n", f.Name)
+		out += "\t\t}\n\t}\n"
+	}
+	out += "}\n"
+
+	return []byte(out), nil
+}
+
+// jsonName returns a field's json tag name, stripping options like
+// ",omitempty".
+func jsonName(f Field) string {
+	name, _, _ := strings.Cut(f.Tags["json"], ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+func taggedFields(s Struct, namespace string) []Field {
+	var out []Field
+	for _, f := range s.AllFields() {
+		if _, ok := f.Tags[namespace]; ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
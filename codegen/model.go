@@ -0,0 +1,128 @@
+// Package codegen generates companion Go source for tagged structs.
+//
+// It walks a directory of Go source files with go/parser, resolves
+// embedded fields by flattening promoted fields the same way the Go
+// compiler does, and hands each struct to a set of Emitters keyed by
+// struct-tag namespace ("json", "db", ...). Built-in emitters produce
+// marshal/unmarshal helpers and SQL SELECT/INSERT builders; callers can
+// register their own Emitter to support additional tag namespaces
+// (e.g. "validate") without forking the generator.
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	"github.com/therecluse26/reflex/internal/astutil"
+)
+
+// Field describes one struct field discovered by the generator.
+type Field struct {
+	Name string
+	Type string
+	// Tags maps a struct-tag namespace (e.g. "json", "db") to its raw
+	// tag value for this field. Fields without a given namespace are
+	// simply absent from the map.
+	Tags map[string]string
+}
+
+// Struct describes one top-level struct declaration.
+type Struct struct {
+	Name string
+	// Fields are the struct's own, directly declared fields.
+	Fields []Field
+	// Promoted are fields brought in through embedding, already
+	// flattened so callers never have to walk embedded types
+	// themselves.
+	Promoted []Field
+}
+
+// AllFields returns the struct's own fields followed by its promoted
+// fields, the order Go itself uses when resolving a selector.
+func (s Struct) AllFields() []Field {
+	out := make([]Field, 0, len(s.Fields)+len(s.Promoted))
+	out = append(out, s.Fields...)
+	out = append(out, s.Promoted...)
+	return out
+}
+
+// Load parses every Go file in dir, type-checks each package it finds
+// with go/types so malformed input is rejected before generation
+// begins (the same gate compiler.CompileFile applies), and returns the
+// structs the packages declare, with embedded fields from other
+// structs in the same directory promoted and flattened. It does not
+// follow embeds into other packages.
+func Load(dir string) ([]Struct, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("codegen: parse %s: %w", dir, err)
+	}
+
+	conf := types.Config{Importer: importer.Default()}
+	for name, pkg := range pkgs {
+		var files []*ast.File
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+		if _, err := conf.Check(name, fset, files, nil); err != nil {
+			return nil, fmt.Errorf("codegen: type-check: %w", err)
+		}
+	}
+
+	raw := map[string]*ast.StructType{}
+	var order []string
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					if _, seen := raw[ts.Name.Name]; !seen {
+						order = append(order, ts.Name.Name)
+					}
+					raw[ts.Name.Name] = st
+				}
+			}
+		}
+	}
+
+	structs := make([]Struct, 0, len(order))
+	for _, name := range order {
+		structs = append(structs, buildStruct(name, raw))
+	}
+	return structs, nil
+}
+
+func buildStruct(name string, raw map[string]*ast.StructType) Struct {
+	s := Struct{Name: name}
+	fields, promoted := astutil.FlattenFields(name, raw[name], raw)
+	s.Fields = toFields(fields)
+	s.Promoted = toFields(promoted)
+	return s
+}
+
+func toFields(fields []astutil.Field) []Field {
+	if fields == nil {
+		return nil
+	}
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i] = Field{Name: f.Name, Type: f.Type, Tags: f.Tags}
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLEmitter emits SELECT/INSERT builders keyed off each field's "db"
+// tag. The table name is the lowercased struct name; callers that need
+// a different mapping can register a replacement Emitter for "db".
+type SQLEmitter struct{}
+
+// Namespace implements Emitter.
+func (SQLEmitter) Namespace() string { return "db" }
+
+// Emit implements Emitter.
+func (SQLEmitter) Emit(s Struct) ([]byte, error) {
+	fields := taggedFields(s, "db")
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	table := strings.ToLower(s.Name)
+	columns := make([]string, len(fields))
+	placeholders := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Tags["db"]
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	columnList := strings.Join(columns, ", ")
+
+	out := fmt.Sprintf("// Select%sSQL returns the SELECT statement for %s, ordered to match %s.Scan%s.\n", s.Name, table, s.Name, s.Name)
+	out += fmt.Sprintf("func Select%sSQL() string {\n", s.Name)
+	out += fmt.Sprintf("\treturn %q\n}\n\n", fmt.Sprintf("SELECT %s FROM %s", columnList, table))
+
+	out += fmt.Sprintf("// Insert%sSQL returns the parameterized INSERT statement for %s.\n", s.Name, table)
+	out += fmt.Sprintf("func Insert%sSQL() string {\n", s.Name)
+	out += fmt.Sprintf("\treturn %q\n}\n\n", fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, columnList, strings.Join(placeholders, ", ")))
+
+	out += fmt.Sprintf("// Scan%s scans a single row, in the column order returned by Select%sSQL, into v.\n", s.Name, s.Name)
+	out += fmt.Sprintf("func Scan%s(row interface{ Scan(dest ...interface{}) error }, v *%s) error {\n", s.Name, s.Name)
+	out += "\treturn row.Scan(\n"
+	for _, f := range fields {
+		out += fmt.Sprintf("\t\t&v.%s,\n", f.Name)
+	}
+	out += "\t)\n}\n"
+
+	return []byte(out), nil
+}
@@ -0,0 +1,35 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateEmitter emits a Validate stub for structs that carry a
+// "validate" tag, listing the fields that need a rule wired up. It
+// exists mainly as a worked example of registering a new tag
+// namespace via Generator.Register, and leaves the actual checks to
+// the user.
+type ValidateEmitter struct{}
+
+// Namespace implements Emitter.
+func (ValidateEmitter) Namespace() string { return "validate" }
+
+// Emit implements Emitter.
+func (ValidateEmitter) Emit(s Struct) ([]byte, error) {
+	fields := taggedFields(s, "validate")
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = fmt.Sprintf("%s (%s)", f.Name, f.Tags["validate"])
+	}
+
+	out := fmt.Sprintf("// Validate is a generated stub for %s's tagged fields: %s.\n", s.Name, strings.Join(names, ", "))
+	out += "// Fill in the checks each rule requires; the stub only returns nil.\n"
+	out += fmt.Sprintf("func (v *%s) Validate() error {\n\treturn nil\n}\n", s.Name)
+
+	return []byte(out), nil
+}
@@ -0,0 +1,205 @@
+package compiler
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+
+	"github.com/therecluse26/reflex/vm"
+)
+
+// nativeArity records how many values each built-in/native call
+// produces, so callers know how many results to expect without full
+// type information. Anything not listed defaults to 1.
+var nativeArity = map[string]int{
+	"fmt.Println": 0,
+	"fmt.Printf":  0,
+	"time.Sleep":  0,
+	"fmt.Sprintf": 1,
+	"fmt.Errorf":  1,
+	"len":         1,
+}
+
+// nativeConsts resolves package-qualified constants the corpus reads
+// as plain values rather than calls, such as time.Millisecond.
+var nativeConsts = map[string]vm.Value{
+	"time.Nanosecond":  vm.Int(1),
+	"time.Microsecond": vm.Int(1_000),
+	"time.Millisecond": vm.Int(1_000_000),
+	"time.Second":      vm.Int(1_000_000_000),
+}
+
+func nativeConst(name string) (vm.Value, bool) {
+	v, ok := nativeConsts[name]
+	return v, ok
+}
+
+// compileCall compiles a call expression and returns how many result
+// values it leaves on the stack.
+func (c *funcCompiler) compileCall(call *ast.CallExpr) (int, error) {
+	if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "len" {
+		if len(call.Args) != 1 {
+			return 0, fmt.Errorf("len takes exactly one argument")
+		}
+		if err := c.compileExpr(call.Args[0]); err != nil {
+			return 0, err
+		}
+		c.emit(vm.Instruction{Op: vm.OpLen})
+		return 1, nil
+	}
+
+	if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "make" {
+		if len(call.Args) == 0 {
+			return 0, fmt.Errorf("make takes at least one argument")
+		}
+		if _, ok := call.Args[0].(*ast.ChanType); !ok {
+			return 0, fmt.Errorf("make only supports channel types in this subset")
+		}
+		buf := 0
+		if len(call.Args) > 1 {
+			lit, ok := call.Args[1].(*ast.BasicLit)
+			if !ok {
+				return 0, fmt.Errorf("make's buffer size must be a literal")
+			}
+			n, err := strconv.Atoi(lit.Value)
+			if err != nil {
+				return 0, fmt.Errorf("invalid channel buffer size %q: %w", lit.Value, err)
+			}
+			buf = n
+		}
+		c.emit(vm.Instruction{Op: vm.OpMakeChan, A: buf})
+		return 1, nil
+	}
+
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && c.isMethodReceiver(sel.X) {
+		if err := c.compileExpr(sel.X); err != nil {
+			return 0, err
+		}
+		argc, err := c.compileArgs(call.Args)
+		if err != nil {
+			return 0, err
+		}
+		c.emit(vm.Instruction{Op: vm.OpCallMethod, Str: sel.Sel.Name, A: argc})
+		return c.methodArity(sel.Sel.Name), nil
+	}
+
+	argc, err := c.compileCallArgs(call)
+	if err != nil {
+		return 0, err
+	}
+	c.emit(vm.Instruction{Op: vm.OpCall, A: argc})
+	return c.calleeArity(call.Fun), nil
+}
+
+// compileCallArgs pushes the callee value followed by its arguments,
+// in the order OpCall/OpDefer/OpGo expect, and returns the argument
+// count. It is shared by plain calls and defer/go statements, neither
+// of which target a method in the corpus.
+func (c *funcCompiler) compileCallArgs(call *ast.CallExpr) (int, error) {
+	if err := c.pushCalleeValue(call.Fun); err != nil {
+		return 0, err
+	}
+	return c.compileArgs(call.Args)
+}
+
+func (c *funcCompiler) compileArgs(args []ast.Expr) (int, error) {
+	for _, arg := range args {
+		if err := c.compileExpr(arg); err != nil {
+			return 0, err
+		}
+	}
+	return len(args), nil
+}
+
+// isMethodReceiver reports whether expr looks like a variable holding
+// a struct value (the receiver of a method call) rather than an
+// imported package qualifier.
+func (c *funcCompiler) isMethodReceiver(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		// Anything other than a bare identifier (e.g. a selector chain)
+		// can only be a receiver expression in this subset.
+		return true
+	}
+	if _, ok := c.resolveLocal(ident.Name); ok {
+		return true
+	}
+	if _, ok := c.resolveUpval(ident.Name); ok {
+		return true
+	}
+	return false
+}
+
+func (c *funcCompiler) pushCalleeValue(fun ast.Expr) error {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return c.loadIdent(f.Name)
+	case *ast.SelectorExpr:
+		ident, ok := f.X.(*ast.Ident)
+		if !ok || c.isMethodReceiver(f.X) {
+			return fmt.Errorf("unsupported callee %v", f)
+		}
+		name := ident.Name + "." + f.Sel.Name
+		c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Value{Kind: vm.KindNative, Str: name})})
+		return nil
+	default:
+		return fmt.Errorf("unsupported callee expression %T", fun)
+	}
+}
+
+func (c *funcCompiler) calleeArity(fun ast.Expr) int {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		if proto, ok := c.prog.Funcs[f.Name]; ok {
+			return proto.NumResults
+		}
+		// Calling through a local variable (e.g. a func-typed
+		// parameter like HigherOrder's fn): the corpus only ever
+		// calls single-result function values this way.
+		return 1
+	case *ast.SelectorExpr:
+		ident, ok := f.X.(*ast.Ident)
+		if ok {
+			if n, ok := nativeArity[ident.Name+"."+f.Sel.Name]; ok {
+				return n
+			}
+		}
+		return 1
+	default:
+		return 1
+	}
+}
+
+// methodArity looks up a method's declared result count. Since the
+// compiler doesn't track full static types for receiver expressions,
+// it resolves by method name alone, which is unambiguous for the
+// corpus (Increment/Value are each declared on exactly one type).
+func (c *funcCompiler) methodArity(method string) int {
+	for _, methods := range c.prog.Methods {
+		if proto, ok := methods[method]; ok {
+			return proto.NumResults
+		}
+	}
+	return 1
+}
+
+// compileCallDiscardingResults compiles expr as a statement, popping
+// whatever results the call produced since nothing consumes them.
+func (c *funcCompiler) compileCallDiscardingResults(expr ast.Expr) (int, error) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		if err := c.compileExpr(expr); err != nil {
+			return 0, err
+		}
+		c.emit(vm.Instruction{Op: vm.OpPop})
+		return 1, nil
+	}
+	n, err := c.compileCall(call)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < n; i++ {
+		c.emit(vm.Instruction{Op: vm.OpPop})
+	}
+	return n, nil
+}
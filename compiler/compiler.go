@@ -0,0 +1,89 @@
+// Package compiler lowers the Go subset exercised by the corpus — the
+// int/string/bool/slice values, structs with fields and methods,
+// functions with multiple and named returns, variadics, closures,
+// defer, and goroutines over channels — into vm.Program bytecode the
+// vm package can run.
+//
+// It type-checks the source with go/types first so unsupported or
+// malformed input is rejected before lowering begins, but the VM's
+// values stay dynamically tagged at run time, so type-checking here
+// is a gate rather than something codegen depends on.
+//
+// Known simplifications versus full Go: embedded fields are reachable
+// only through the embedding field's own name (no promotion at run
+// time — the compiler package does the promotion, the vm package does
+// not), "&Expr{...}" is compiled identically to "Expr{...}" because
+// struct values are always heap-boxed, and "&&"/"||" evaluate both
+// operands eagerly rather than short-circuiting.
+package compiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+
+	"github.com/therecluse26/reflex/vm"
+)
+
+// CompileFile type-checks file and lowers every function declaration
+// it contains into a vm.Program.
+func CompileFile(fset *token.FileSet, file *ast.File) (*vm.Program, error) {
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, nil); err != nil {
+		return nil, fmt.Errorf("compiler: type-check: %w", err)
+	}
+
+	prog := vm.NewProgram()
+
+	var funcDecls []*ast.FuncDecl
+	for _, decl := range file.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok {
+			funcDecls = append(funcDecls, fd)
+		}
+	}
+
+	// Pass 1: register a proto for every free function and method so
+	// forward and mutually recursive references - including one method
+	// calling another method of the same type declared later in the
+	// file - resolve to a stable pointer with the correct result arity
+	// already known from the AST signature, rather than depending on
+	// prog.Funcs/prog.Methods having been populated by the time the
+	// caller compiles.
+	for _, fd := range funcDecls {
+		numResults := countResults(fd.Type)
+		if fd.Recv == nil {
+			prog.Funcs[fd.Name.Name] = &vm.FuncProto{Name: fd.Name.Name, NumResults: numResults}
+			continue
+		}
+		if typeName, ok := receiverTypeName(fd); ok {
+			prog.AddMethod(typeName, &vm.FuncProto{Name: fd.Name.Name, NumResults: numResults})
+		}
+	}
+
+	// Pass 2: compile bodies in place, filling in the protos pass 1
+	// registered.
+	for _, fd := range funcDecls {
+		var proto *vm.FuncProto
+		if fd.Recv == nil {
+			proto = prog.Funcs[fd.Name.Name]
+		} else if typeName, ok := receiverTypeName(fd); ok {
+			proto = prog.Methods[typeName][fd.Name.Name]
+		}
+		if proto == nil {
+			proto = &vm.FuncProto{Name: fd.Name.Name}
+		}
+
+		fc := newFuncCompiler(nil, proto, prog)
+		typeName, err := fc.compileFuncDecl(fd)
+		if err != nil {
+			return nil, fmt.Errorf("compiler: %s: %w", fd.Name.Name, err)
+		}
+		if typeName != "" {
+			prog.AddMethod(typeName, proto)
+		}
+	}
+
+	return prog, nil
+}
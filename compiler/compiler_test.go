@@ -0,0 +1,258 @@
+package compiler
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/therecluse26/reflex/vm"
+)
+
+func compileCorpus(t *testing.T, path string) *vm.Program {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	prog, err := CompileFile(fset, file)
+	if err != nil {
+		t.Fatalf("CompileFile: %v", err)
+	}
+	return prog
+}
+
+func TestArithmeticAndControlFlow(t *testing.T) {
+	prog := compileCorpus(t, "../testdata/corpus/go/functions.go")
+	m := vm.New(prog)
+
+	if res, err := m.Run("Add", vm.Int(5), vm.Int(10)); err != nil || res[0].Int != 15 {
+		t.Fatalf("Add(5, 10) = %v, %v; want 15, nil", res, err)
+	}
+	if res, err := m.Run("Sum", vm.Int(1), vm.Int(2), vm.Int(3), vm.Int(4), vm.Int(5)); err != nil || res[0].Int != 15 {
+		t.Fatalf("Sum(1..5) = %v, %v; want 15, nil", res, err)
+	}
+	if res, err := m.Run("Calculate", vm.Int(10), vm.Int(3), vm.String("mul")); err != nil || res[0].Int != 30 {
+		t.Fatalf("Calculate(10,3,mul) = %v, %v; want 30, nil", res, err)
+	}
+}
+
+func TestMultipleAndNamedReturns(t *testing.T) {
+	prog := compileCorpus(t, "../testdata/corpus/go/functions.go")
+	m := vm.New(prog)
+
+	res, err := m.Run("Divide", vm.Int(10), vm.Int(2))
+	if err != nil || res[0].Int != 5 || res[1].Kind != vm.KindNil {
+		t.Fatalf("Divide(10,2) = %v, %v; want [5 nil]", res, err)
+	}
+
+	res, err = m.Run("Divide", vm.Int(1), vm.Int(0))
+	if err != nil || res[1].Kind != vm.KindString {
+		t.Fatalf("Divide(1,0) = %v, %v; want a string error", res, err)
+	}
+
+	nums := []vm.Value{vm.Int(1), vm.Int(5), vm.Int(3), vm.Int(9), vm.Int(2)}
+	res, err = m.Run("Stats", vm.Value{Kind: vm.KindSlice, Slice: &vm.SliceValue{Elems: nums}})
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if res[0].Int != 1 || res[1].Int != 9 || res[2].Int != 4 {
+		t.Fatalf("Stats(1,5,3,9,2) = min %d max %d avg %d; want 1 9 4", res[0].Int, res[1].Int, res[2].Int)
+	}
+}
+
+func TestClosureCapturesByReference(t *testing.T) {
+	prog := compileCorpus(t, "../testdata/corpus/go/functions.go")
+	m := vm.New(prog)
+
+	res, err := m.Run("OuterFunction", vm.Int(5))
+	if err != nil {
+		t.Fatalf("OuterFunction(5): %v", err)
+	}
+	closure := res[0]
+	if closure.Kind != vm.KindClosure {
+		t.Fatalf("OuterFunction(5) returned kind %d, want KindClosure", closure.Kind)
+	}
+
+	got, err := m.Call(closure, vm.Int(10))
+	if err != nil || got[0].Int != 15 {
+		t.Fatalf("closure(10) = %v, %v; want 15, nil", got, err)
+	}
+}
+
+func TestCounterMethodsPointerVsValueReceiver(t *testing.T) {
+	prog := compileCorpus(t, "../testdata/corpus/go/structs.go")
+	m := vm.New(prog)
+
+	counter := vm.Value{Kind: vm.KindStruct, Struct: &vm.StructValue{TypeName: "Counter", Fields: map[string]vm.Value{"value": vm.Int(0)}}}
+
+	if _, err := m.CallMethod(counter, "Increment"); err != nil {
+		t.Fatalf("Increment: %v", err)
+	}
+	if _, err := m.CallMethod(counter, "Increment"); err != nil {
+		t.Fatalf("Increment: %v", err)
+	}
+	res, err := m.CallMethod(counter, "Value")
+	if err != nil || res[0].Int != 2 {
+		t.Fatalf("Value() = %v, %v; want 2, nil", res, err)
+	}
+}
+
+func TestExampleUsageRunsEndToEnd(t *testing.T) {
+	// functions.go's ExampleUsage exercises nearly the whole subset in
+	// one function: multi-return, named/naked returns, a closure,
+	// defer, and a goroutine rendezvousing over a channel.
+	prog := compileCorpus(t, "../testdata/corpus/go/functions.go")
+	m := vm.New(prog)
+
+	if _, err := m.Run("ExampleUsage"); err != nil {
+		t.Fatalf("ExampleUsage(): %v", err)
+	}
+	m.Wait()
+}
+
+func TestForwardAndMutuallyRecursiveCallsGetCorrectArity(t *testing.T) {
+	src := `package corpus
+
+func A() (int, int) {
+	return B()
+}
+
+func B() (int, int) {
+	return 1, 2
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	prog, err := CompileFile(fset, file)
+	if err != nil {
+		t.Fatalf("CompileFile: %v", err)
+	}
+
+	m := vm.New(prog)
+	res, err := m.Run("A")
+	if err != nil {
+		t.Fatalf("A(): %v", err)
+	}
+	if len(res) != 2 || res[0].Int != 1 || res[1].Int != 2 {
+		t.Fatalf("A() = %v; want [1 2]", res)
+	}
+}
+
+func TestMethodCallingLaterMethodOfSameTypeGetsCorrectArity(t *testing.T) {
+	// A calls B before B is declared, the same way the free-function
+	// case above does, but between two methods of the same type: B's
+	// proto must already carry its real NumResults (0) when A compiles,
+	// or compileCallDiscardingResults emits one OpPop too many and the
+	// VM panics popping an empty stack.
+	src := `package corpus
+
+type T struct {
+	value int
+}
+
+func (t T) A() int {
+	x := 99
+	t.B()
+	return x
+}
+
+func (t T) B() {
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	prog, err := CompileFile(fset, file)
+	if err != nil {
+		t.Fatalf("CompileFile: %v", err)
+	}
+
+	m := vm.New(prog)
+	receiver := vm.Value{Kind: vm.KindStruct, Struct: &vm.StructValue{TypeName: "T", Fields: map[string]vm.Value{"value": vm.Int(0)}}}
+	res, err := m.CallMethod(receiver, "A")
+	if err != nil {
+		t.Fatalf("A(): %v", err)
+	}
+	if len(res) != 1 || res[0].Int != 99 {
+		t.Fatalf("A() = %v; want [99]", res)
+	}
+}
+
+func TestValueReceiverCloneDoesNotShareNestedStructFields(t *testing.T) {
+	// A value-receiver method must get its own copy of the whole
+	// receiver, including struct-typed fields, not just the top-level
+	// one; otherwise mutating a nested field leaks back to the caller.
+	src := `package corpus
+
+type Inner struct {
+	n int
+}
+
+type Outer struct {
+	in Inner
+}
+
+func (o Outer) BumpValue() int {
+	o.in.n++
+	return o.in.n
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	prog, err := CompileFile(fset, file)
+	if err != nil {
+		t.Fatalf("CompileFile: %v", err)
+	}
+
+	m := vm.New(prog)
+	inner := &vm.StructValue{TypeName: "Inner", Fields: map[string]vm.Value{"n": vm.Int(1)}}
+	outer := vm.Value{Kind: vm.KindStruct, Struct: &vm.StructValue{TypeName: "Outer", Fields: map[string]vm.Value{"in": {Kind: vm.KindStruct, Struct: inner}}}}
+
+	res, err := m.CallMethod(outer, "BumpValue")
+	if err != nil {
+		t.Fatalf("BumpValue(): %v", err)
+	}
+	if res[0].Int != 2 {
+		t.Fatalf("BumpValue() = %v; want 2", res[0].Int)
+	}
+	if inner.Fields["n"].Int != 1 {
+		t.Fatalf("caller's Inner.n = %d; want unchanged 1 (value receiver must not share nested struct storage)", inner.Fields["n"].Int)
+	}
+}
+
+func TestCompileFileRejectsTypeErrors(t *testing.T) {
+	src := `package corpus
+
+func BadTypes() int {
+	var x string = 5
+	return x
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if _, err := CompileFile(fset, file); err == nil {
+		t.Fatal("CompileFile: want a type error, got nil")
+	}
+}
+
+func TestEmbeddedFieldsAreReachableThroughTheEmbedName(t *testing.T) {
+	prog := compileCorpus(t, "../testdata/corpus/go/structs.go")
+	if prog.Methods == nil {
+		t.Fatal("expected methods map to be initialized")
+	}
+	if _, ok := prog.Methods["Counter"]["Increment"]; !ok {
+		t.Fatal("Counter.Increment was not compiled")
+	}
+}
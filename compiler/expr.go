@@ -0,0 +1,264 @@
+package compiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+
+	"github.com/therecluse26/reflex/vm"
+)
+
+// compileExpr compiles expr so that it leaves exactly the values it
+// naturally produces on the stack: one for most expressions, or the
+// callee's declared result count for a call used as a statement's sole
+// right-hand side (see compileAssign).
+func (c *funcCompiler) compileExpr(expr ast.Expr) error {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return c.compileBasicLit(e)
+
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Bool(true))})
+			return nil
+		case "false":
+			c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Bool(false))})
+			return nil
+		case "nil":
+			c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Nil)})
+			return nil
+		default:
+			return c.loadIdent(e.Name)
+		}
+
+	case *ast.ParenExpr:
+		return c.compileExpr(e.X)
+
+	case *ast.BinaryExpr:
+		return c.compileBinary(e)
+
+	case *ast.UnaryExpr:
+		return c.compileUnary(e)
+
+	case *ast.CallExpr:
+		_, err := c.compileCall(e)
+		return err
+
+	case *ast.SelectorExpr:
+		if ident, ok := e.X.(*ast.Ident); ok && !c.isMethodReceiver(ident) {
+			val, ok := nativeConst(ident.Name + "." + e.Sel.Name)
+			if !ok {
+				return fmt.Errorf("undefined package constant %s.%s", ident.Name, e.Sel.Name)
+			}
+			c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(val)})
+			return nil
+		}
+		if err := c.compileExpr(e.X); err != nil {
+			return err
+		}
+		c.emit(vm.Instruction{Op: vm.OpGetField, Str: e.Sel.Name})
+		return nil
+
+	case *ast.IndexExpr:
+		if err := c.compileExpr(e.X); err != nil {
+			return err
+		}
+		if err := c.compileExpr(e.Index); err != nil {
+			return err
+		}
+		c.emit(vm.Instruction{Op: vm.OpIndex})
+		return nil
+
+	case *ast.CompositeLit:
+		return c.compileCompositeLit(e)
+
+	case *ast.FuncLit:
+		nested, err := c.compileFuncLit(e)
+		if err != nil {
+			return err
+		}
+		idx := c.addConst(vm.Value{Kind: vm.KindProto, Proto: nested})
+		c.emit(vm.Instruction{Op: vm.OpMakeClosure, A: idx})
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported expression type %T", expr)
+	}
+}
+
+func (c *funcCompiler) compileBasicLit(lit *ast.BasicLit) error {
+	switch lit.Kind {
+	case token.INT:
+		n, err := strconv.ParseInt(lit.Value, 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid int literal %q: %w", lit.Value, err)
+		}
+		c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Int(n))})
+		return nil
+	case token.STRING:
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return fmt.Errorf("invalid string literal %q: %w", lit.Value, err)
+		}
+		c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.String(s))})
+		return nil
+	case token.FLOAT:
+		f, err := strconv.ParseFloat(lit.Value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid float literal %q: %w", lit.Value, err)
+		}
+		c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Float(f))})
+		return nil
+	default:
+		return fmt.Errorf("unsupported literal kind %s", lit.Kind)
+	}
+}
+
+func (c *funcCompiler) compileBinary(e *ast.BinaryExpr) error {
+	if err := c.compileExpr(e.X); err != nil {
+		return err
+	}
+	if err := c.compileExpr(e.Y); err != nil {
+		return err
+	}
+	op, ok := binaryOp(e.Op)
+	if !ok {
+		return fmt.Errorf("unsupported binary operator %s", e.Op)
+	}
+	c.emit(vm.Instruction{Op: op})
+	return nil
+}
+
+func binaryOp(tok token.Token) (vm.Op, bool) {
+	switch tok {
+	case token.ADD:
+		return vm.OpAdd, true
+	case token.SUB:
+		return vm.OpSub, true
+	case token.MUL:
+		return vm.OpMul, true
+	case token.QUO:
+		return vm.OpQuo, true
+	case token.REM:
+		return vm.OpRem, true
+	case token.EQL:
+		return vm.OpEQ, true
+	case token.NEQ:
+		return vm.OpNE, true
+	case token.LSS:
+		return vm.OpLT, true
+	case token.LEQ:
+		return vm.OpLE, true
+	case token.GTR:
+		return vm.OpGT, true
+	case token.GEQ:
+		return vm.OpGE, true
+	case token.LAND:
+		return vm.OpAnd, true
+	case token.LOR:
+		return vm.OpOr, true
+	default:
+		return 0, false
+	}
+}
+
+func (c *funcCompiler) compileUnary(e *ast.UnaryExpr) error {
+	if e.Op == token.AND {
+		// Struct values are always heap-boxed (see package doc), so
+		// "&Expr{...}" compiles exactly like "Expr{...}".
+		return c.compileExpr(e.X)
+	}
+	if e.Op == token.ARROW {
+		if err := c.compileExpr(e.X); err != nil {
+			return err
+		}
+		c.emit(vm.Instruction{Op: vm.OpRecv})
+		return nil
+	}
+	if err := c.compileExpr(e.X); err != nil {
+		return err
+	}
+	switch e.Op {
+	case token.NOT:
+		c.emit(vm.Instruction{Op: vm.OpNot})
+	case token.SUB:
+		c.emit(vm.Instruction{Op: vm.OpNeg})
+	default:
+		return fmt.Errorf("unsupported unary operator %s", e.Op)
+	}
+	return nil
+}
+
+// loadIdent pushes the current value of a variable, or a closure value
+// referring to a top-level function if name isn't a variable.
+func (c *funcCompiler) loadIdent(name string) error {
+	if idx, ok := c.resolveLocal(name); ok {
+		c.emit(vm.Instruction{Op: vm.OpLoadLocal, A: idx})
+		return nil
+	}
+	if idx, ok := c.resolveUpval(name); ok {
+		c.emit(vm.Instruction{Op: vm.OpLoadUpval, A: idx})
+		return nil
+	}
+	if proto, ok := c.prog.Funcs[name]; ok {
+		c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Value{Kind: vm.KindClosure, Closure: &vm.Closure{Proto: proto}})})
+		return nil
+	}
+	return fmt.Errorf("undefined identifier %q", name)
+}
+
+func (c *funcCompiler) compileCompositeLit(lit *ast.CompositeLit) error {
+	switch t := lit.Type.(type) {
+	case *ast.Ident:
+		return c.compileStructLit(t.Name, lit)
+	case *ast.StructType:
+		return c.compileStructLit("", lit)
+	case *ast.ArrayType:
+		for _, elt := range lit.Elts {
+			if err := c.compileExpr(elt); err != nil {
+				return err
+			}
+		}
+		c.emit(vm.Instruction{Op: vm.OpNewSlice, A: len(lit.Elts)})
+		return nil
+	default:
+		return fmt.Errorf("unsupported composite literal type %T", lit.Type)
+	}
+}
+
+func (c *funcCompiler) compileStructLit(typeName string, lit *ast.CompositeLit) error {
+	names := make([]string, 0, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return fmt.Errorf("struct literal for %s must use field: value elements", typeName)
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			return fmt.Errorf("unsupported struct literal key %T", kv.Key)
+		}
+		if err := c.compileExpr(kv.Value); err != nil {
+			return err
+		}
+		names = append(names, key.Name)
+	}
+	c.emit(vm.Instruction{Op: vm.OpNewStruct, Str: typeName, Strs: names})
+	return nil
+}
+
+// compileFuncLit compiles a function literal into its own FuncProto,
+// nested lexically under c so it can resolve free variables as
+// upvalues captured from c's locals (or c's own upvalues).
+func (c *funcCompiler) compileFuncLit(fl *ast.FuncLit) (*vm.FuncProto, error) {
+	proto := &vm.FuncProto{Name: "<closure>"}
+	nc := newFuncCompiler(c, proto, c.prog)
+	nc.compileSignature(fl.Type)
+	if err := nc.compileStmts(fl.Body.List); err != nil {
+		return nil, err
+	}
+	nc.emitImplicitReturn()
+	proto.NumLocals = nc.numLocals
+	return proto, nil
+}
@@ -0,0 +1,228 @@
+package compiler
+
+import (
+	"fmt"
+	"go/ast"
+
+	"github.com/therecluse26/reflex/vm"
+)
+
+// funcCompiler compiles a single function or method body into proto,
+// tracking the local-variable scope needed to resolve identifiers and
+// the link to its lexical parent needed to resolve upvalues for
+// nested function literals.
+type funcCompiler struct {
+	parent *funcCompiler
+	proto  *vm.FuncProto
+	prog   *vm.Program
+
+	localSlots map[string]int
+	numLocals  int
+}
+
+func newFuncCompiler(parent *funcCompiler, proto *vm.FuncProto, prog *vm.Program) *funcCompiler {
+	return &funcCompiler{parent: parent, proto: proto, prog: prog, localSlots: map[string]int{}}
+}
+
+func (c *funcCompiler) newLocal(name string) int {
+	if idx, ok := c.localSlots[name]; ok {
+		return idx
+	}
+	idx := c.numLocals
+	c.localSlots[name] = idx
+	c.numLocals++
+	return idx
+}
+
+func (c *funcCompiler) resolveLocal(name string) (int, bool) {
+	idx, ok := c.localSlots[name]
+	return idx, ok
+}
+
+// resolveUpval finds name in an ancestor function's scope, threading a
+// capture through every intervening level, and returns its index in
+// this function's own Upvalues.
+func (c *funcCompiler) resolveUpval(name string) (int, bool) {
+	if c.parent == nil {
+		return 0, false
+	}
+	for i, n := range c.proto.UpvalNames {
+		if n == name {
+			return i, true
+		}
+	}
+	if idx, ok := c.parent.resolveLocal(name); ok {
+		return c.addUpval(name, vm.UpvalSource{FromParentLocal: true, Index: idx}), true
+	}
+	if idx, ok := c.parent.resolveUpval(name); ok {
+		return c.addUpval(name, vm.UpvalSource{FromParentLocal: false, Index: idx}), true
+	}
+	return 0, false
+}
+
+func (c *funcCompiler) addUpval(name string, src vm.UpvalSource) int {
+	c.proto.UpvalNames = append(c.proto.UpvalNames, name)
+	c.proto.UpvalSources = append(c.proto.UpvalSources, src)
+	return len(c.proto.UpvalNames) - 1
+}
+
+func (c *funcCompiler) emit(ins vm.Instruction) int {
+	c.proto.Code = append(c.proto.Code, ins)
+	return len(c.proto.Code) - 1
+}
+
+func (c *funcCompiler) patchJump(at int, target int) {
+	c.proto.Code[at].A = target
+}
+
+func (c *funcCompiler) here() int { return len(c.proto.Code) }
+
+func (c *funcCompiler) addConst(v vm.Value) int {
+	c.proto.Consts = append(c.proto.Consts, v)
+	return len(c.proto.Consts) - 1
+}
+
+// receiverTypeName returns a method declaration's receiver type name,
+// the same way compileFuncDecl resolves it, without compiling
+// anything. CompileFile's pass 1 uses this to pre-register every
+// method's proto (and thus its NumResults) before any body compiles.
+func receiverTypeName(fd *ast.FuncDecl) (string, bool) {
+	if fd.Recv == nil {
+		return "", false
+	}
+	typeExpr := fd.Recv.List[0].Type
+	if star, ok := typeExpr.(*ast.StarExpr); ok {
+		typeExpr = star.X
+	}
+	ident, ok := typeExpr.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// compileFuncDecl compiles fd's signature and body into c.proto,
+// returning the receiver's type name for methods or "" for free
+// functions.
+func (c *funcCompiler) compileFuncDecl(fd *ast.FuncDecl) (string, error) {
+	proto := c.proto
+	var typeName string
+
+	if fd.Recv != nil {
+		recvField := fd.Recv.List[0]
+		name := "_"
+		if len(recvField.Names) > 0 {
+			name = recvField.Names[0].Name
+		}
+		pointer := false
+		typeExpr := recvField.Type
+		if star, ok := typeExpr.(*ast.StarExpr); ok {
+			pointer = true
+			typeExpr = star.X
+		}
+		ident, ok := typeExpr.(*ast.Ident)
+		if !ok {
+			return "", fmt.Errorf("unsupported receiver type")
+		}
+		typeName = ident.Name
+		c.newLocal(name)
+		proto.Receiver = &vm.ReceiverInfo{Name: name, TypeName: typeName, Pointer: pointer}
+	}
+
+	c.compileSignature(fd.Type)
+
+	if fd.Body == nil {
+		return "", fmt.Errorf("function has no body")
+	}
+	if err := c.compileStmts(fd.Body.List); err != nil {
+		return "", err
+	}
+
+	// A function with named results can fall off the end with a bare
+	// "return" already compiled, but if control simply runs past the
+	// last statement (no trailing return at all), fall back to
+	// returning the named results' current values, matching Go's
+	// requirement that this only happens when results are named.
+	c.emitImplicitReturn()
+
+	proto.NumLocals = c.numLocals
+	return typeName, nil
+}
+
+// compileSignature declares local slots for a function or closure
+// literal's parameters and named results, in order, and records their
+// arity on c.proto. It is shared by compileFuncDecl and the func
+// literal path in expr.go.
+func (c *funcCompiler) compileSignature(ft *ast.FuncType) {
+	proto := c.proto
+	for _, field := range ft.Params.List {
+		_, variadic := field.Type.(*ast.Ellipsis)
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent("_")}
+		}
+		for _, n := range names {
+			c.newLocal(n.Name)
+			proto.ParamNames = append(proto.ParamNames, n.Name)
+			proto.NumParams++
+		}
+		if variadic {
+			proto.Variadic = true
+		}
+	}
+
+	if ft.Results != nil {
+		for _, field := range ft.Results.List {
+			if len(field.Names) == 0 {
+				proto.ResultNames = append(proto.ResultNames, "")
+				continue
+			}
+			for _, n := range field.Names {
+				c.newLocal(n.Name)
+				proto.ResultNames = append(proto.ResultNames, n.Name)
+			}
+		}
+	}
+	proto.NumResults = len(proto.ResultNames)
+}
+
+// countResults returns a function type's result arity straight from
+// its AST signature, without compiling it. Pass 1 uses this to give
+// every free-function proto a correct NumResults before any body
+// (including forward or mutually recursive callers) is compiled.
+func countResults(ft *ast.FuncType) int {
+	if ft.Results == nil {
+		return 0
+	}
+	n := 0
+	for _, field := range ft.Results.List {
+		if len(field.Names) == 0 {
+			n++
+			continue
+		}
+		n += len(field.Names)
+	}
+	return n
+}
+
+// emitImplicitReturn is a safety net appended after every compiled
+// body: if control already left via an explicit return, this code is
+// simply unreachable; if it fell off the end (only legal when every
+// result is named), it returns the named results' current values.
+//
+// A function with any unnamed result can never legally fall off the
+// end — go/types rejects that in CompileFile before compilation
+// reaches here — so this emits nothing in that case rather than
+// resolving a name that was never declared as a local.
+func (c *funcCompiler) emitImplicitReturn() {
+	for _, name := range c.proto.ResultNames {
+		if name == "" {
+			return
+		}
+	}
+	for _, name := range c.proto.ResultNames {
+		idx, _ := c.resolveLocal(name)
+		c.emit(vm.Instruction{Op: vm.OpLoadLocal, A: idx})
+	}
+	c.emit(vm.Instruction{Op: vm.OpReturn, A: len(c.proto.ResultNames)})
+}
@@ -0,0 +1,470 @@
+package compiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"github.com/therecluse26/reflex/vm"
+)
+
+func (c *funcCompiler) compileStmts(stmts []ast.Stmt) error {
+	for _, s := range stmts {
+		if err := c.compileStmt(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *funcCompiler) compileStmt(stmt ast.Stmt) error {
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		n, err := c.compileCallDiscardingResults(s.X)
+		if err != nil {
+			return err
+		}
+		_ = n
+		return nil
+
+	case *ast.DeclStmt:
+		return c.compileDeclStmt(s)
+
+	case *ast.AssignStmt:
+		return c.compileAssign(s)
+
+	case *ast.IncDecStmt:
+		return c.compileIncDec(s)
+
+	case *ast.ReturnStmt:
+		return c.compileReturn(s)
+
+	case *ast.IfStmt:
+		return c.compileIf(s)
+
+	case *ast.ForStmt:
+		return c.compileFor(s)
+
+	case *ast.RangeStmt:
+		return c.compileRange(s)
+
+	case *ast.SwitchStmt:
+		return c.compileSwitch(s)
+
+	case *ast.BlockStmt:
+		return c.compileStmts(s.List)
+
+	case *ast.DeferStmt:
+		return c.compileDefer(s)
+
+	case *ast.GoStmt:
+		return c.compileGo(s)
+
+	case *ast.SendStmt:
+		if err := c.compileExpr(s.Chan); err != nil {
+			return err
+		}
+		if err := c.compileExpr(s.Value); err != nil {
+			return err
+		}
+		c.emit(vm.Instruction{Op: vm.OpSend})
+		return nil
+
+	case *ast.BranchStmt:
+		return fmt.Errorf("unsupported branch statement %s", s.Tok)
+
+	default:
+		return fmt.Errorf("unsupported statement type %T", stmt)
+	}
+}
+
+func (c *funcCompiler) compileDeclStmt(s *ast.DeclStmt) error {
+	gen, ok := s.Decl.(*ast.GenDecl)
+	if !ok || gen.Tok != token.VAR {
+		return fmt.Errorf("unsupported declaration")
+	}
+	for _, spec := range gen.Specs {
+		vs := spec.(*ast.ValueSpec)
+		for i, name := range vs.Names {
+			slot := c.newLocal(name.Name)
+			if i < len(vs.Values) {
+				if err := c.compileExpr(vs.Values[i]); err != nil {
+					return err
+				}
+				c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: slot})
+			} else {
+				c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Int(0))})
+				c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: slot})
+			}
+		}
+	}
+	return nil
+}
+
+func (c *funcCompiler) compileAssign(s *ast.AssignStmt) error {
+	if s.Tok != token.DEFINE && s.Tok != token.ASSIGN {
+		return c.compileCompoundAssign(s)
+	}
+
+	// Multi-value assignment from a single call: `a, b := f()`.
+	if len(s.Rhs) == 1 && len(s.Lhs) > 1 {
+		if err := c.compileExpr(s.Rhs[0]); err != nil {
+			return err
+		}
+		for i := len(s.Lhs) - 1; i >= 0; i-- {
+			if err := c.storeTo(s.Lhs[i], s.Tok == token.DEFINE); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, lhs := range s.Lhs {
+		if err := c.compileExpr(s.Rhs[i]); err != nil {
+			return err
+		}
+		if err := c.storeTo(lhs, s.Tok == token.DEFINE); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *funcCompiler) compileCompoundAssign(s *ast.AssignStmt) error {
+	ident, ok := s.Lhs[0].(*ast.Ident)
+	if !ok {
+		return fmt.Errorf("unsupported compound-assignment target %T", s.Lhs[0])
+	}
+	op, ok := compoundOp(s.Tok)
+	if !ok {
+		return fmt.Errorf("unsupported assignment operator %s", s.Tok)
+	}
+	if err := c.loadIdent(ident.Name); err != nil {
+		return err
+	}
+	if err := c.compileExpr(s.Rhs[0]); err != nil {
+		return err
+	}
+	c.emit(vm.Instruction{Op: op})
+	return c.storeTo(ident, false)
+}
+
+func compoundOp(tok token.Token) (vm.Op, bool) {
+	switch tok {
+	case token.ADD_ASSIGN:
+		return vm.OpAdd, true
+	case token.SUB_ASSIGN:
+		return vm.OpSub, true
+	case token.MUL_ASSIGN:
+		return vm.OpMul, true
+	case token.QUO_ASSIGN:
+		return vm.OpQuo, true
+	case token.REM_ASSIGN:
+		return vm.OpRem, true
+	default:
+		return 0, false
+	}
+}
+
+// storeTo compiles a store into an assignment target already on top of
+// the stack: a bare identifier (declaring it first if define is true
+// and it doesn't exist yet) or a selector's field.
+func (c *funcCompiler) storeTo(target ast.Expr, define bool) error {
+	switch t := target.(type) {
+	case *ast.Ident:
+		if t.Name == "_" {
+			c.emit(vm.Instruction{Op: vm.OpPop})
+			return nil
+		}
+		if define {
+			slot := c.newLocal(t.Name)
+			c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: slot})
+			return nil
+		}
+		if idx, ok := c.resolveLocal(t.Name); ok {
+			c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: idx})
+			return nil
+		}
+		if idx, ok := c.resolveUpval(t.Name); ok {
+			c.emit(vm.Instruction{Op: vm.OpStoreUpval, A: idx})
+			return nil
+		}
+		slot := c.newLocal(t.Name)
+		c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: slot})
+		return nil
+
+	case *ast.SelectorExpr:
+		return c.storeField(t)
+
+	default:
+		return fmt.Errorf("unsupported assignment target %T", target)
+	}
+}
+
+// storeField is called with the value to store already on top of the
+// stack. OpSetField pops (value, struct) in that order, so pushing the
+// base struct expression now puts it on top as required.
+func (c *funcCompiler) storeField(sel *ast.SelectorExpr) error {
+	if err := c.compileExpr(sel.X); err != nil {
+		return err
+	}
+	c.emit(vm.Instruction{Op: vm.OpSetField, Str: sel.Sel.Name})
+	return nil
+}
+
+func (c *funcCompiler) compileIncDec(s *ast.IncDecStmt) error {
+	step := func() {
+		c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Int(1))})
+		if s.Tok == token.INC {
+			c.emit(vm.Instruction{Op: vm.OpAdd})
+		} else {
+			c.emit(vm.Instruction{Op: vm.OpSub})
+		}
+	}
+
+	switch target := s.X.(type) {
+	case *ast.Ident:
+		if err := c.loadIdent(target.Name); err != nil {
+			return err
+		}
+		step()
+		return c.storeTo(target, false)
+
+	case *ast.SelectorExpr:
+		if err := c.compileExpr(target.X); err != nil {
+			return err
+		}
+		c.emit(vm.Instruction{Op: vm.OpDup})
+		c.emit(vm.Instruction{Op: vm.OpGetField, Str: target.Sel.Name})
+		step()
+		c.emit(vm.Instruction{Op: vm.OpSetField, Str: target.Sel.Name})
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported inc/dec target %T", s.X)
+	}
+}
+
+// compileSwitch lowers an expression switch into a chain of
+// equality-or-boolean tests and conditional jumps: "case v1, v2:" on a
+// tagged switch becomes (tag==v1 || tag==v2); a tagless switch treats
+// each case expression as the condition directly.
+func (c *funcCompiler) compileSwitch(s *ast.SwitchStmt) error {
+	if s.Init != nil {
+		if err := c.compileStmt(s.Init); err != nil {
+			return err
+		}
+	}
+
+	hasTag := s.Tag != nil
+	var tagSlot int
+	if hasTag {
+		if err := c.compileExpr(s.Tag); err != nil {
+			return err
+		}
+		tagSlot = c.newLocal(fmt.Sprintf("$switch%d", c.here()))
+		c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: tagSlot})
+	}
+
+	var endJumps []int
+	var defaultClause *ast.CaseClause
+	for _, stmt := range s.Body.List {
+		cc := stmt.(*ast.CaseClause)
+		if cc.List == nil {
+			defaultClause = cc
+			continue
+		}
+
+		for i, v := range cc.List {
+			if hasTag {
+				c.emit(vm.Instruction{Op: vm.OpLoadLocal, A: tagSlot})
+			}
+			if err := c.compileExpr(v); err != nil {
+				return err
+			}
+			if hasTag {
+				c.emit(vm.Instruction{Op: vm.OpEQ})
+			}
+			if i > 0 {
+				c.emit(vm.Instruction{Op: vm.OpOr})
+			}
+		}
+		jumpNext := c.emit(vm.Instruction{Op: vm.OpJumpIfFalse})
+		if err := c.compileStmts(cc.Body); err != nil {
+			return err
+		}
+		endJumps = append(endJumps, c.emit(vm.Instruction{Op: vm.OpJump}))
+		c.patchJump(jumpNext, c.here())
+	}
+
+	if defaultClause != nil {
+		if err := c.compileStmts(defaultClause.Body); err != nil {
+			return err
+		}
+	}
+
+	end := c.here()
+	for _, j := range endJumps {
+		c.patchJump(j, end)
+	}
+	return nil
+}
+
+func (c *funcCompiler) compileReturn(s *ast.ReturnStmt) error {
+	if len(s.Results) == 0 {
+		// Naked return: read back whatever the named results hold.
+		for _, name := range c.proto.ResultNames {
+			if err := c.loadIdent(name); err != nil {
+				return err
+			}
+		}
+		c.emit(vm.Instruction{Op: vm.OpReturn, A: len(c.proto.ResultNames)})
+		return nil
+	}
+	// A single result expression may itself be a multi-result call, as
+	// in `return B()` where B returns two values: compile it through
+	// compileCall and return exactly what it leaves on the stack,
+	// mirroring how compileAssign treats `a, b := f()`.
+	if len(s.Results) == 1 {
+		if call, ok := s.Results[0].(*ast.CallExpr); ok {
+			n, err := c.compileCall(call)
+			if err != nil {
+				return err
+			}
+			c.emit(vm.Instruction{Op: vm.OpReturn, A: n})
+			return nil
+		}
+	}
+
+	for _, expr := range s.Results {
+		if err := c.compileExpr(expr); err != nil {
+			return err
+		}
+	}
+	c.emit(vm.Instruction{Op: vm.OpReturn, A: len(s.Results)})
+	return nil
+}
+
+func (c *funcCompiler) compileIf(s *ast.IfStmt) error {
+	if s.Init != nil {
+		if err := c.compileStmt(s.Init); err != nil {
+			return err
+		}
+	}
+	if err := c.compileExpr(s.Cond); err != nil {
+		return err
+	}
+	jumpElse := c.emit(vm.Instruction{Op: vm.OpJumpIfFalse})
+	if err := c.compileStmt(s.Body); err != nil {
+		return err
+	}
+	if s.Else != nil {
+		jumpEnd := c.emit(vm.Instruction{Op: vm.OpJump})
+		c.patchJump(jumpElse, c.here())
+		if err := c.compileStmt(s.Else); err != nil {
+			return err
+		}
+		c.patchJump(jumpEnd, c.here())
+	} else {
+		c.patchJump(jumpElse, c.here())
+	}
+	return nil
+}
+
+func (c *funcCompiler) compileFor(s *ast.ForStmt) error {
+	if s.Init != nil {
+		if err := c.compileStmt(s.Init); err != nil {
+			return err
+		}
+	}
+	condPC := c.here()
+	var jumpEnd int
+	haveJumpEnd := false
+	if s.Cond != nil {
+		if err := c.compileExpr(s.Cond); err != nil {
+			return err
+		}
+		jumpEnd = c.emit(vm.Instruction{Op: vm.OpJumpIfFalse})
+		haveJumpEnd = true
+	}
+	if err := c.compileStmt(s.Body); err != nil {
+		return err
+	}
+	if s.Post != nil {
+		if err := c.compileStmt(s.Post); err != nil {
+			return err
+		}
+	}
+	c.emit(vm.Instruction{Op: vm.OpJump, A: condPC})
+	if haveJumpEnd {
+		c.patchJump(jumpEnd, c.here())
+	}
+	return nil
+}
+
+// compileRange supports the one shape the subset needs: ranging over a
+// slice-valued expression, binding a blank or named index and value.
+func (c *funcCompiler) compileRange(s *ast.RangeStmt) error {
+	if err := c.compileExpr(s.X); err != nil {
+		return err
+	}
+	sliceSlot := c.newLocal(fmt.Sprintf("$range%d", c.here()))
+	c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: sliceSlot})
+
+	idxName := "_"
+	if id, ok := s.Key.(*ast.Ident); ok {
+		idxName = id.Name
+	}
+	idxSlot := c.newLocal(idxName)
+	c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Int(0))})
+	c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: idxSlot})
+
+	condPC := c.here()
+	c.emit(vm.Instruction{Op: vm.OpLoadLocal, A: idxSlot})
+	c.emit(vm.Instruction{Op: vm.OpLoadLocal, A: sliceSlot})
+	c.emit(vm.Instruction{Op: vm.OpLen})
+	c.emit(vm.Instruction{Op: vm.OpLT})
+	jumpEnd := c.emit(vm.Instruction{Op: vm.OpJumpIfFalse})
+
+	if s.Value != nil {
+		valName := "_"
+		if id, ok := s.Value.(*ast.Ident); ok {
+			valName = id.Name
+		}
+		valSlot := c.newLocal(valName)
+		c.emit(vm.Instruction{Op: vm.OpLoadLocal, A: sliceSlot})
+		c.emit(vm.Instruction{Op: vm.OpLoadLocal, A: idxSlot})
+		c.emit(vm.Instruction{Op: vm.OpIndex})
+		c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: valSlot})
+	}
+
+	if err := c.compileStmt(s.Body); err != nil {
+		return err
+	}
+
+	c.emit(vm.Instruction{Op: vm.OpLoadLocal, A: idxSlot})
+	c.emit(vm.Instruction{Op: vm.OpConst, A: c.addConst(vm.Int(1))})
+	c.emit(vm.Instruction{Op: vm.OpAdd})
+	c.emit(vm.Instruction{Op: vm.OpStoreLocal, A: idxSlot})
+	c.emit(vm.Instruction{Op: vm.OpJump, A: condPC})
+	c.patchJump(jumpEnd, c.here())
+	return nil
+}
+
+func (c *funcCompiler) compileDefer(s *ast.DeferStmt) error {
+	argc, err := c.compileCallArgs(s.Call)
+	if err != nil {
+		return err
+	}
+	c.emit(vm.Instruction{Op: vm.OpDefer, A: argc})
+	return nil
+}
+
+func (c *funcCompiler) compileGo(s *ast.GoStmt) error {
+	argc, err := c.compileCallArgs(s.Call)
+	if err != nil {
+		return err
+	}
+	c.emit(vm.Instruction{Op: vm.OpGo, A: argc})
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package astutil holds the struct-field flattening and tag-parsing
+// logic shared by codegen, symbols, and analyzer: rendering a type
+// expression back to source-ish text, parsing a struct tag into its
+// per-namespace values, and promoting an embedded field's own fields
+// up into its embedder the way the Go compiler resolves selectors.
+package astutil
+
+import (
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Field is the minimal shape FlattenFields produces for one struct
+// field. Callers convert it to their own package's richer Field type,
+// which always has the same three pieces of data.
+type Field struct {
+	Name string
+	Type string
+	Tags map[string]string
+}
+
+// FlattenFields splits st's field list - the struct named selfName in
+// raw - into its own fields and, for embedded fields naming another
+// struct in raw, that struct's own fields promoted and flattened.
+//
+// A struct that embeds itself, directly or transitively through a
+// cycle of embeds, stops promoting once it revisits a name already on
+// the current path rather than recursing forever; go/parser alone
+// (with no go/types check) happily parses such a cycle, so this guard
+// is load-bearing even though real Go source can never exhibit one.
+func FlattenFields(selfName string, st *ast.StructType, raw map[string]*ast.StructType) (fields, promoted []Field) {
+	return flattenFields(selfName, st, raw, map[string]bool{selfName: true})
+}
+
+func flattenFields(selfName string, st *ast.StructType, raw map[string]*ast.StructType, visited map[string]bool) (fields, promoted []Field) {
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			embedded, ok := EmbeddedTypeName(f.Type)
+			if !ok || visited[embedded] {
+				continue
+			}
+			if embeddedStruct, known := raw[embedded]; known {
+				// Copy rather than mutate visited: it tracks the
+				// current ancestor path, not every name promoted
+				// anywhere in the tree, so a diamond (two siblings
+				// both embedding the same base struct) still resolves
+				// both independently.
+				childVisited := make(map[string]bool, len(visited)+1)
+				for k := range visited {
+					childVisited[k] = true
+				}
+				childVisited[embedded] = true
+				embFields, embPromoted := flattenFields(embedded, embeddedStruct, raw, childVisited)
+				promoted = append(promoted, embFields...)
+				promoted = append(promoted, embPromoted...)
+			}
+			continue
+		}
+		typ := TypeString(f.Type)
+		tags := ParseTags(f.Tag)
+		for _, n := range f.Names {
+			fields = append(fields, Field{Name: n.Name, Type: typ, Tags: tags})
+		}
+	}
+	return fields, promoted
+}
+
+// EmbeddedTypeName returns the type name an embedded field's type
+// expression refers to, e.g. "Base" for both "Base" and "*Base".
+func EmbeddedTypeName(expr ast.Expr) (string, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name, true
+	case *ast.StarExpr:
+		return EmbeddedTypeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name, true
+	default:
+		return "", false
+	}
+}
+
+// TypeString renders a type expression the way a field's Type string
+// is reported: close to source syntax, falling back to "interface{}"
+// for anything this subset doesn't otherwise describe.
+func TypeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + TypeString(t.X)
+	case *ast.ArrayType:
+		return "[]" + TypeString(t.Elt)
+	case *ast.Ellipsis:
+		return "..." + TypeString(t.Elt)
+	case *ast.SelectorExpr:
+		return TypeString(t.X) + "." + t.Sel.Name
+	case *ast.ChanType:
+		return "chan " + TypeString(t.Value)
+	case *ast.FuncType:
+		return "func(...)"
+	case *ast.StructType:
+		return "struct{...}"
+	case *ast.InterfaceType:
+		return "interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+// ParseTags splits a struct tag into its per-namespace values, e.g.
+// `json:"id" db:"user_id"` becomes {"json": "id", "db": "user_id"}.
+func ParseTags(tag *ast.BasicLit) map[string]string {
+	if tag == nil {
+		return nil
+	}
+	raw, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return nil
+	}
+	st := reflect.StructTag(raw)
+	tags := map[string]string{}
+	for _, ns := range strings.Fields(raw) {
+		name := ns
+		if i := strings.IndexByte(name, ':'); i >= 0 {
+			name = name[:i]
+		}
+		if v, ok := st.Lookup(name); ok {
+			tags[name] = v
+		}
+	}
+	return tags
+}
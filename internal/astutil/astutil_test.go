@@ -0,0 +1,125 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+	"time"
+)
+
+func parseStructs(t *testing.T, src string) map[string]*ast.StructType {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", "package p\n"+src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	raw := map[string]*ast.StructType{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				raw[ts.Name.Name] = st
+			}
+		}
+	}
+	return raw
+}
+
+func TestFlattenFieldsPromotesEmbedded(t *testing.T) {
+	raw := parseStructs(t, `
+type Base struct {
+	Name string
+}
+type Derived struct {
+	Base
+	Age int
+}
+`)
+	fields, promoted := FlattenFields("Derived", raw["Derived"], raw)
+	if len(fields) != 1 || fields[0].Name != "Age" {
+		t.Errorf("fields = %+v, want just Age", fields)
+	}
+	if len(promoted) != 1 || promoted[0].Name != "Name" {
+		t.Errorf("promoted = %+v, want just Name", promoted)
+	}
+}
+
+func TestFlattenFieldsResolvesDiamondEmbedding(t *testing.T) {
+	// Two siblings embedding the same base struct is legal, ordinary Go
+	// (unlike the cycle below) and must promote Base's fields through
+	// both of them independently.
+	raw := parseStructs(t, `
+type Base struct {
+	ID int
+}
+type Mid1 struct {
+	Base
+	X int
+}
+type Mid2 struct {
+	Base
+	Y int
+}
+type Top struct {
+	Mid1
+	Mid2
+}
+`)
+	_, promoted := FlattenFields("Top", raw["Top"], raw)
+	var names []string
+	for _, f := range promoted {
+		names = append(names, f.Name)
+	}
+	want := []string{"X", "ID", "Y", "ID"}
+	if len(names) != len(want) {
+		t.Fatalf("promoted = %v, want %v", names, want)
+	}
+	for i, n := range want {
+		if names[i] != n {
+			t.Errorf("promoted[%d] = %q, want %q (full: %v)", i, names[i], n, names)
+		}
+	}
+}
+
+func TestFlattenFieldsStopsOnEmbedCycle(t *testing.T) {
+	// go/parser alone happily parses a mutually-embedding pair of
+	// structs even though go/types would reject it; FlattenFields must
+	// not recurse forever on input like this.
+	raw := parseStructs(t, `
+type A struct {
+	B
+	X int
+}
+type B struct {
+	A
+	Y int
+}
+`)
+	done := make(chan struct{})
+	var fields, promoted []Field
+	go func() {
+		fields, promoted = FlattenFields("A", raw["A"], raw)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("FlattenFields did not return; embed cycle caused unbounded recursion")
+	}
+
+	if len(fields) != 1 || fields[0].Name != "X" {
+		t.Errorf("fields = %+v, want just X", fields)
+	}
+	if len(promoted) != 1 || promoted[0].Name != "Y" {
+		t.Errorf("promoted = %+v, want just Y (B's own field, promotion stops before A.B re-recurses)", promoted)
+	}
+}
@@ -0,0 +1,213 @@
+// Package pipeline runs symbol extraction over many files concurrently.
+//
+// A Runner wires up the classic Go pipeline pattern: a source stage
+// streams file paths, a pool of workers independently parses each file
+// with go/parser.ParseFile and extracts its symbols, and a sink
+// collates the results back into path order. Bounded channels provide
+// backpressure, and a context.Context can cancel the whole run early.
+//
+// WithVisitors additionally drives a shared analyzer.Analyzer's
+// registered Visitors over each file a worker parses, so a worker pool
+// can run the analyzer's visitor pipeline over a large corpus without
+// every Visitor needing to know about the pool itself.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/therecluse26/reflex/analyzer"
+	"github.com/therecluse26/reflex/symbols"
+)
+
+// FileResult is one file's outcome, sent in the order its path
+// appeared in the Run call regardless of which worker finished it, or
+// when.
+type FileResult struct {
+	Path    string
+	Symbols []symbols.Symbol
+	Err     error
+}
+
+// Stats reports a Runner's progress and throughput. It is safe to read
+// concurrently with a Run in flight by calling Runner.Stats.
+type Stats struct {
+	FilesQueued    int
+	FilesProcessed int
+	Errors         int
+	Elapsed        time.Duration
+}
+
+// FilesPerSecond is FilesProcessed divided by Elapsed, or 0 before any
+// time has elapsed.
+func (s Stats) FilesPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.FilesProcessed) / s.Elapsed.Seconds()
+}
+
+// Runner fans a list of files across a worker pool for concurrent
+// symbol extraction. The zero value is not usable; build one with New.
+type Runner struct {
+	workers  int
+	visitors *analyzer.Analyzer
+	visitMu  sync.Mutex // serializes AnalyzeFiles calls; Visitors must not run concurrently with one another
+
+	queued    int64
+	processed int64
+	errors    int64
+	start     time.Time
+	elapsed   int64 // nanoseconds, set once the run finishes
+}
+
+// Option configures a Runner constructed with New.
+type Option func(*Runner)
+
+// WithVisitors registers Visitors that, for every file a worker
+// parses, receive the same callbacks analyzer.Analyzer.Analyze would
+// dispatch for it. Calls into the Visitors are serialized across the
+// whole Runner, preserving the analyzer package's guarantee that
+// Visitors are never invoked concurrently with one another even though
+// the workers parsing files run in parallel.
+func WithVisitors(vs ...analyzer.Visitor) Option {
+	return func(r *Runner) {
+		opts := make([]analyzer.Option, len(vs))
+		for i, v := range vs {
+			opts[i] = analyzer.WithVisitor(v)
+		}
+		r.visitors = analyzer.New(opts...)
+	}
+}
+
+// New returns a Runner with the given worker count. n <= 0 defaults to
+// runtime.NumCPU().
+func New(n int, opts ...Option) *Runner {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	r := &Runner{workers: n}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run extracts symbols from every path concurrently and streams the
+// results, one per path, in the same order paths were given. The
+// returned channel is closed once every path has been processed or ctx
+// is canceled, whichever comes first; a canceled run's remaining
+// results carry ctx.Err().
+//
+// Run may only be called once per Runner.
+func (r *Runner) Run(ctx context.Context, paths []string) <-chan FileResult {
+	r.start = time.Now()
+	atomic.StoreInt64(&r.queued, int64(len(paths)))
+
+	type indexedPath struct {
+		index int
+		path  string
+	}
+
+	pathCh := make(chan indexedPath, r.workers)
+	go func() {
+		defer close(pathCh)
+		for i, p := range paths {
+			select {
+			case <-ctx.Done():
+				return
+			case pathCh <- indexedPath{index: i, path: p}:
+			}
+		}
+	}()
+
+	// One buffered slot per path lets workers finish out of order
+	// while the sink below still emits results in path order.
+	slots := make([]chan FileResult, len(paths))
+	for i := range slots {
+		slots[i] = make(chan FileResult, 1)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < r.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ip := range pathCh {
+				if err := ctx.Err(); err != nil {
+					slots[ip.index] <- FileResult{Path: ip.path, Err: err}
+					continue
+				}
+				syms, err := symbols.ExtractFile(ip.path)
+				if err == nil && r.visitors != nil {
+					err = r.runVisitors(ip.path)
+				}
+				atomic.AddInt64(&r.processed, 1)
+				if err != nil {
+					atomic.AddInt64(&r.errors, 1)
+				}
+				slots[ip.index] <- FileResult{Path: ip.path, Symbols: syms, Err: err}
+			}
+		}()
+	}
+
+	out := make(chan FileResult, r.workers)
+	go func() {
+		defer close(out)
+		defer atomic.StoreInt64(&r.elapsed, int64(time.Since(r.start)))
+		defer wg.Wait()
+		for i, slot := range slots {
+			select {
+			case res := <-slot:
+				out <- res
+			case <-ctx.Done():
+				// A canceled producer may never have handed this path
+				// to a worker at all, so nothing will ever arrive on
+				// slot; emit ctx.Err() for it (and every slot after
+				// it) instead of leaving it unfilled.
+				out <- FileResult{Path: paths[i], Err: ctx.Err()}
+			}
+		}
+	}()
+
+	return out
+}
+
+// runVisitors parses path and dispatches it to the Visitors registered
+// with WithVisitors, the same way analyzer.Analyzer.Analyze would for
+// a whole directory. Calls are serialized across the Runner so
+// Visitors only ever see one file at a time, even though the parse
+// above runs on whichever worker goroutine got this path.
+func (r *Runner) runVisitors(path string) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("pipeline: parse %s: %w", path, err)
+	}
+
+	r.visitMu.Lock()
+	defer r.visitMu.Unlock()
+	return r.visitors.AnalyzeFiles([]*ast.File{file})
+}
+
+// Stats returns a snapshot of the run's progress so far. It is safe to
+// call before, during, or after Run.
+func (r *Runner) Stats() Stats {
+	elapsed := time.Duration(atomic.LoadInt64(&r.elapsed))
+	if elapsed == 0 && !r.start.IsZero() {
+		elapsed = time.Since(r.start)
+	}
+	return Stats{
+		FilesQueued:    int(atomic.LoadInt64(&r.queued)),
+		FilesProcessed: int(atomic.LoadInt64(&r.processed)),
+		Errors:         int(atomic.LoadInt64(&r.errors)),
+		Elapsed:        elapsed,
+	}
+}
@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/therecluse26/reflex/analyzer"
+)
+
+var corpusFiles = []string{
+	"../testdata/corpus/go/structs.go",
+	"../testdata/corpus/go/functions.go",
+}
+
+func collect(ch <-chan FileResult) []FileResult {
+	var out []FileResult
+	for r := range ch {
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestRunPreservesPathOrder(t *testing.T) {
+	r := New(4)
+	results := collect(r.Run(context.Background(), corpusFiles))
+
+	if len(results) != len(corpusFiles) {
+		t.Fatalf("got %d results, want %d", len(results), len(corpusFiles))
+	}
+	for i, res := range results {
+		if res.Path != corpusFiles[i] {
+			t.Errorf("results[%d].Path = %q, want %q", i, res.Path, corpusFiles[i])
+		}
+		if res.Err != nil {
+			t.Errorf("results[%d].Err = %v, want nil", i, res.Err)
+		}
+		if len(res.Symbols) == 0 {
+			t.Errorf("results[%d].Symbols is empty for %q", i, res.Path)
+		}
+	}
+}
+
+func TestRunDefaultsWorkersWhenNonPositive(t *testing.T) {
+	r := New(0)
+	if r.workers <= 0 {
+		t.Fatalf("workers = %d, want > 0", r.workers)
+	}
+}
+
+func TestStatsReportsProcessedCount(t *testing.T) {
+	r := New(2)
+	collect(r.Run(context.Background(), corpusFiles))
+
+	stats := r.Stats()
+	if stats.FilesQueued != len(corpusFiles) {
+		t.Errorf("FilesQueued = %d, want %d", stats.FilesQueued, len(corpusFiles))
+	}
+	if stats.FilesProcessed != len(corpusFiles) {
+		t.Errorf("FilesProcessed = %d, want %d", stats.FilesProcessed, len(corpusFiles))
+	}
+	if stats.Errors != 0 {
+		t.Errorf("Errors = %d, want 0", stats.Errors)
+	}
+	if stats.Elapsed <= 0 {
+		t.Error("Elapsed should be > 0 after a completed run")
+	}
+}
+
+func TestRunCancellationStopsEarly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := New(2)
+	results := collect(r.Run(ctx, corpusFiles))
+
+	if len(results) != len(corpusFiles) {
+		t.Fatalf("got %d results, want %d (a canceled run must still produce one result per path)", len(results), len(corpusFiles))
+	}
+	for _, res := range results {
+		if res.Err == nil {
+			t.Errorf("result for %q should carry the cancellation error", res.Path)
+		}
+	}
+}
+
+func TestRunReportsParseErrors(t *testing.T) {
+	r := New(2)
+	results := collect(r.Run(context.Background(), []string{"does/not/exist.go"}))
+
+	if len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("results = %+v, want a single result with a parse error", results)
+	}
+	if r.Stats().Errors != 1 {
+		t.Errorf("Stats().Errors = %d, want 1", r.Stats().Errors)
+	}
+}
+
+// recordingVisitor records every struct name it sees; tests only need
+// to confirm the Runner actually drives the visitor pipeline, not
+// exercise every callback (analyzer's own tests do that).
+type recordingVisitor struct {
+	analyzer.BaseVisitor
+
+	mu      sync.Mutex
+	structs []string
+}
+
+func (v *recordingVisitor) VisitStruct(s analyzer.StructInfo) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.structs = append(v.structs, s.Name)
+}
+
+func TestRunWithVisitorsDispatchesPerFile(t *testing.T) {
+	v := &recordingVisitor{}
+	r := New(4, WithVisitors(v))
+	results := collect(r.Run(context.Background(), corpusFiles))
+
+	for _, res := range results {
+		if res.Err != nil {
+			t.Errorf("results for %q: %v", res.Path, res.Err)
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	found := false
+	for _, name := range v.structs {
+		if name == "Employee" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("structs = %v, want Employee present", v.structs)
+	}
+}
+
+func TestFilesPerSecond(t *testing.T) {
+	s := Stats{FilesProcessed: 10, Elapsed: 2 * time.Second}
+	if got := s.FilesPerSecond(); got != 5 {
+		t.Errorf("FilesPerSecond() = %v, want 5", got)
+	}
+	if (Stats{}).FilesPerSecond() != 0 {
+		t.Error("FilesPerSecond() on the zero value should be 0")
+	}
+}
@@ -0,0 +1,195 @@
+// Package symbols extracts structured, JSON-serializable records for
+// the declarations in a directory of Go source files.
+//
+// Extract walks a package with go/parser and records every top-level
+// struct and function declaration as a Symbol, flattening embedded
+// fields the same way the Go compiler resolves promoted selectors, and
+// also records anonymous struct literals found inside function bodies.
+// The resulting []Symbol has a stable JSON shape (see Symbol) so
+// downstream tools can consume it without depending on this package,
+// and can be narrowed with Filter's small query DSL.
+package symbols
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/therecluse26/reflex/internal/astutil"
+)
+
+// Extract parses every Go file in pkgPath and returns a Symbol for
+// each top-level struct and function declaration, plus one for every
+// anonymous struct literal found inside a function body. It does not
+// follow embeds into other packages.
+func Extract(pkgPath string) ([]Symbol, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, pkgPath, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("symbols: parse %s: %w", pkgPath, err)
+	}
+
+	var files []*ast.File
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+	}
+	return extractFromFiles(files), nil
+}
+
+// ExtractFile parses the single Go file at path and returns a Symbol
+// for each declaration it finds, the same way Extract does. Because it
+// only ever sees one file, embedded fields naming a struct declared in
+// a sibling file of the same package are left unresolved (Promoted is
+// empty for them) - the tradeoff that lets a worker pool extract
+// symbols from many files independently, in parallel, with no shared
+// state. See the pipeline package for such a pool.
+func ExtractFile(path string) ([]Symbol, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("symbols: parse %s: %w", path, err)
+	}
+	return extractFromFiles([]*ast.File{file}), nil
+}
+
+func extractFromFiles(files []*ast.File) []Symbol {
+	raw := map[string]*ast.StructType{}
+	var structOrder []string
+	var funcs []*ast.FuncDecl
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					if _, seen := raw[ts.Name.Name]; !seen {
+						structOrder = append(structOrder, ts.Name.Name)
+					}
+					raw[ts.Name.Name] = st
+				}
+			case *ast.FuncDecl:
+				funcs = append(funcs, d)
+			}
+		}
+	}
+
+	var symbols []Symbol
+	for _, name := range structOrder {
+		symbols = append(symbols, buildStructSymbol(name, raw))
+	}
+	for _, fd := range funcs {
+		symbols = append(symbols, buildFuncSymbol(fd))
+		symbols = append(symbols, anonymousStructsIn(fd)...)
+	}
+	return symbols
+}
+
+func buildStructSymbol(name string, raw map[string]*ast.StructType) Symbol {
+	s := Symbol{Kind: KindStruct, Name: name}
+	fields, promoted := astutil.FlattenFields(name, raw[name], raw)
+	s.Fields = toFields(fields)
+	s.Promoted = toFields(promoted)
+	return s
+}
+
+func toFields(fields []astutil.Field) []Field {
+	if fields == nil {
+		return nil
+	}
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i] = Field{Name: f.Name, Type: f.Type, Tags: f.Tags}
+	}
+	return out
+}
+
+func buildFuncSymbol(fd *ast.FuncDecl) Symbol {
+	s := Symbol{Kind: KindFunc, Name: fd.Name.Name}
+
+	if fd.Recv != nil && len(fd.Recv.List) == 1 {
+		recv := fd.Recv.List[0]
+		name := ""
+		if len(recv.Names) > 0 {
+			name = recv.Names[0].Name
+		}
+		typ := astutil.TypeString(recv.Type)
+		s.Receiver = &Receiver{
+			Name:    name,
+			Type:    strings.TrimPrefix(typ, "*"),
+			Pointer: strings.HasPrefix(typ, "*"),
+		}
+	}
+
+	params := fd.Type.Params.List
+	for i, p := range params {
+		typ := astutil.TypeString(p.Type)
+		if i == len(params)-1 {
+			if _, ok := p.Type.(*ast.Ellipsis); ok {
+				s.Variadic = true
+			}
+		}
+		if len(p.Names) == 0 {
+			s.Params = append(s.Params, Field{Type: typ})
+			continue
+		}
+		for _, n := range p.Names {
+			s.Params = append(s.Params, Field{Name: n.Name, Type: typ})
+		}
+	}
+
+	if fd.Type.Results != nil {
+		for _, r := range fd.Type.Results.List {
+			typ := astutil.TypeString(r.Type)
+			if len(r.Names) == 0 {
+				s.Results = append(s.Results, Field{Type: typ})
+				continue
+			}
+			for _, n := range r.Names {
+				s.Results = append(s.Results, Field{Name: n.Name, Type: typ})
+			}
+		}
+	}
+
+	return s
+}
+
+// anonymousStructsIn walks fd's body for composite literals typed by
+// an inline struct type, recording each as its own Symbol.
+func anonymousStructsIn(fd *ast.FuncDecl) []Symbol {
+	if fd.Body == nil {
+		return nil
+	}
+	var out []Symbol
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		st, ok := lit.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		fields, _ := astutil.FlattenFields("", st, nil)
+		out = append(out, Symbol{
+			Kind:          KindAnonymousStruct,
+			EnclosingFunc: fd.Name.Name,
+			Fields:        toFields(fields),
+		})
+		return true
+	})
+	return out
+}
@@ -0,0 +1,61 @@
+package symbols
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filter narrows symbols to those matching query, a space-separated
+// list of "key:value" terms that must all match (an AND). Supported
+// keys are:
+//
+//   - kind:<kind>   matches Symbol.Kind exactly, e.g. "kind:struct"
+//   - name:<name>   matches Symbol.Name exactly
+//   - tag:<ns>      matches if any field (own, promoted, or anonymous)
+//     carries a tag in namespace ns, e.g. "tag:json"
+//
+// An empty query matches every symbol.
+func Filter(symbols []Symbol, query string) ([]Symbol, error) {
+	terms := strings.Fields(query)
+	preds := make([]func(Symbol) bool, 0, len(terms))
+	for _, term := range terms {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok {
+			return nil, fmt.Errorf("symbols: invalid filter term %q, want key:value", term)
+		}
+		switch key {
+		case "kind":
+			preds = append(preds, func(s Symbol) bool { return string(s.Kind) == value })
+		case "name":
+			preds = append(preds, func(s Symbol) bool { return s.Name == value })
+		case "tag":
+			preds = append(preds, func(s Symbol) bool { return hasTag(s, value) })
+		default:
+			return nil, fmt.Errorf("symbols: unknown filter key %q", key)
+		}
+	}
+
+	var out []Symbol
+	for _, s := range symbols {
+		match := true
+		for _, pred := range preds {
+			if !pred(s) {
+				match = false
+				break
+			}
+		}
+		if match {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func hasTag(s Symbol, namespace string) bool {
+	for _, f := range s.AllFields() {
+		if _, ok := f.Tags[namespace]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package symbols
+
+// Kind identifies what kind of declaration a Symbol describes.
+type Kind string
+
+const (
+	KindStruct          Kind = "struct"
+	KindFunc            Kind = "func"
+	KindAnonymousStruct Kind = "anonymous_struct"
+)
+
+// Field describes one struct field or function parameter/result.
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Tags maps a struct-tag namespace (e.g. "json", "db") to its raw
+	// tag value for this field. Absent for parameters and results,
+	// and for fields without any tags.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// Receiver describes a method's receiver.
+type Receiver struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Pointer bool   `json:"pointer"`
+}
+
+// Symbol is the stable, JSON-serializable record Extract produces for
+// one top-level declaration (or, for anonymous structs, one literal
+// found inside a function body). Which fields are populated depends
+// on Kind:
+//
+//   - KindStruct: Fields, Promoted.
+//   - KindAnonymousStruct: Fields, EnclosingFunc.
+//   - KindFunc: Receiver (if a method), Params, Results, Variadic.
+type Symbol struct {
+	Kind Kind   `json:"kind"`
+	Name string `json:"name,omitempty"`
+
+	// Fields are a struct's own, directly declared fields.
+	Fields []Field `json:"fields,omitempty"`
+	// Promoted are fields brought in through embedding, already
+	// flattened so callers never have to walk embedded types
+	// themselves. Only set for KindStruct.
+	Promoted []Field `json:"promoted,omitempty"`
+	// EnclosingFunc is the name of the function an anonymous struct
+	// literal was found inside. Only set for KindAnonymousStruct.
+	EnclosingFunc string `json:"enclosing_func,omitempty"`
+
+	Receiver *Receiver `json:"receiver,omitempty"`
+	Params   []Field   `json:"params,omitempty"`
+	Results  []Field   `json:"results,omitempty"`
+	Variadic bool      `json:"variadic,omitempty"`
+}
+
+// AllFields returns the symbol's own fields followed by its promoted
+// fields, the order Go itself uses when resolving a selector.
+func (s Symbol) AllFields() []Field {
+	out := make([]Field, 0, len(s.Fields)+len(s.Promoted))
+	out = append(out, s.Fields...)
+	out = append(out, s.Promoted...)
+	return out
+}
@@ -0,0 +1,150 @@
+package symbols
+
+import "testing"
+
+const corpusDir = "../testdata/corpus/go"
+
+func findByName(symbols []Symbol, kind Kind, name string) *Symbol {
+	for i := range symbols {
+		if symbols[i].Kind == kind && symbols[i].Name == name {
+			return &symbols[i]
+		}
+	}
+	return nil
+}
+
+func TestExtractFlattensPromotedFields(t *testing.T) {
+	syms, err := Extract(corpusDir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	employee := findByName(syms, KindStruct, "Employee")
+	if employee == nil {
+		t.Fatal("Employee struct not found")
+	}
+	if len(employee.Promoted) != 3 {
+		t.Fatalf("len(Promoted) = %d, want 3 (Name, Age, Email promoted from Person)", len(employee.Promoted))
+	}
+	if employee.Promoted[0].Name != "Name" {
+		t.Errorf("Promoted[0].Name = %q, want Name", employee.Promoted[0].Name)
+	}
+}
+
+func TestExtractParsesTagsPerNamespace(t *testing.T) {
+	syms, err := Extract(corpusDir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	user := findByName(syms, KindStruct, "User")
+	if user == nil {
+		t.Fatal("User struct not found")
+	}
+	var id *Field
+	for i := range user.Fields {
+		if user.Fields[i].Name == "ID" {
+			id = &user.Fields[i]
+		}
+	}
+	if id == nil {
+		t.Fatal("ID field not found")
+	}
+	if id.Tags["json"] != "id" || id.Tags["db"] != "user_id" {
+		t.Errorf("ID.Tags = %+v, want json:id db:user_id", id.Tags)
+	}
+}
+
+func TestExtractRecordsReceiverPointerVsValue(t *testing.T) {
+	syms, err := Extract(corpusDir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	inc := findByName(syms, KindFunc, "Increment")
+	if inc == nil || inc.Receiver == nil {
+		t.Fatal("Increment method (with receiver) not found")
+	}
+	if !inc.Receiver.Pointer || inc.Receiver.Type != "Counter" {
+		t.Errorf("Increment.Receiver = %+v, want pointer receiver on Counter", inc.Receiver)
+	}
+
+	val := findByName(syms, KindFunc, "Value")
+	if val == nil || val.Receiver == nil {
+		t.Fatal("Value method (with receiver) not found")
+	}
+	if val.Receiver.Pointer {
+		t.Errorf("Value.Receiver.Pointer = true, want false (value receiver)")
+	}
+}
+
+func TestExtractRecordsVariadicAndNamedReturns(t *testing.T) {
+	syms, err := Extract(corpusDir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	sum := findByName(syms, KindFunc, "Sum")
+	if sum == nil || !sum.Variadic {
+		t.Fatal("Sum should be recorded as variadic")
+	}
+
+	stats := findByName(syms, KindFunc, "Stats")
+	if stats == nil {
+		t.Fatal("Stats function not found")
+	}
+	if len(stats.Results) != 3 || stats.Results[0].Name != "min" {
+		t.Errorf("Stats.Results = %+v, want named min, max, avg", stats.Results)
+	}
+}
+
+func TestExtractFindsAnonymousStructLiteral(t *testing.T) {
+	syms, err := Extract(corpusDir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	var anon *Symbol
+	for i := range syms {
+		if syms[i].Kind == KindAnonymousStruct && syms[i].EnclosingFunc == "CreateAnonymous" {
+			anon = &syms[i]
+		}
+	}
+	if anon == nil {
+		t.Fatal("anonymous struct literal in CreateAnonymous not found")
+	}
+	if len(anon.Fields) != 2 || anon.Fields[0].Name != "Name" || anon.Fields[1].Name != "Age" {
+		t.Errorf("anon.Fields = %+v, want Name, Age", anon.Fields)
+	}
+}
+
+func TestFilterByKindAndTag(t *testing.T) {
+	syms, err := Extract(corpusDir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	structs, err := Filter(syms, "kind:struct")
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	for _, s := range structs {
+		if s.Kind != KindStruct {
+			t.Errorf("Filter(kind:struct) returned a %s", s.Kind)
+		}
+	}
+
+	tagged, err := Filter(syms, "kind:struct tag:json")
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].Name != "User" {
+		t.Errorf("Filter(kind:struct tag:json) = %+v, want only User", tagged)
+	}
+}
+
+func TestFilterRejectsMalformedTerm(t *testing.T) {
+	if _, err := Filter(nil, "bogus"); err == nil {
+		t.Error("Filter() with a term missing ':' should return an error")
+	}
+}
@@ -19,8 +19,6 @@
 
 package corpus
 
-import "encoding/json"
-
 // Point is a basic struct
 type Point struct {
 	X float64
@@ -89,7 +87,7 @@ func CreateAnonymous() interface{} {
 }
 
 // Example of struct initialization
-func ExampleUsage() {
+func ExampleStructUsage() {
 	// Regular initialization
 	p := Point{X: 1.0, Y: 2.0}
 
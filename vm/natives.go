@@ -0,0 +1,65 @@
+package vm
+
+import (
+	"fmt"
+	"time"
+)
+
+// NativeFunc is a host function the VM can call by name, bridging
+// compiled code out to real Go. Qualified calls like fmt.Println or
+// time.Sleep compile to a lookup against this table rather than being
+// interpreted by the VM itself.
+type NativeFunc func(args []Value) ([]Value, error)
+
+// DefaultNatives returns the small set of standard-library calls the
+// corpus relies on. Callers embedding the VM can extend or replace
+// this table via VM.Natives.
+func DefaultNatives() map[string]NativeFunc {
+	return map[string]NativeFunc{
+		"fmt.Println": func(args []Value) ([]Value, error) {
+			anys := make([]interface{}, len(args))
+			for i, a := range args {
+				anys[i] = a.String()
+			}
+			fmt.Println(anys...)
+			return nil, nil
+		},
+		"fmt.Printf": func(args []Value) ([]Value, error) {
+			if len(args) == 0 {
+				return nil, nil
+			}
+			anys := make([]interface{}, len(args)-1)
+			for i, a := range args[1:] {
+				anys[i] = a.String()
+			}
+			fmt.Printf(args[0].Str, anys...)
+			return nil, nil
+		},
+		"fmt.Sprintf": func(args []Value) ([]Value, error) {
+			if len(args) == 0 {
+				return []Value{String("")}, nil
+			}
+			anys := make([]interface{}, len(args)-1)
+			for i, a := range args[1:] {
+				anys[i] = a.String()
+			}
+			return []Value{String(fmt.Sprintf(args[0].Str, anys...))}, nil
+		},
+		"fmt.Errorf": func(args []Value) ([]Value, error) {
+			if len(args) == 0 {
+				return []Value{Nil}, nil
+			}
+			anys := make([]interface{}, len(args)-1)
+			for i, a := range args[1:] {
+				anys[i] = a.String()
+			}
+			return []Value{String(fmt.Sprintf(args[0].Str, anys...))}, nil
+		},
+		"time.Sleep": func(args []Value) ([]Value, error) {
+			if len(args) > 0 {
+				time.Sleep(time.Duration(args[0].Int))
+			}
+			return nil, nil
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package vm
+
+// Op is a single bytecode operation.
+type Op int
+
+const (
+	OpConst      Op = iota // push Consts[A]
+	OpLoadLocal            // push *locals[A]
+	OpStoreLocal           // pop into *locals[A]
+	OpLoadUpval            // push *Upvalues[A]
+	OpStoreUpval           // pop into *Upvalues[A]
+	OpPop                  // discard top of stack
+	OpDup                  // duplicate top of stack
+
+	OpAdd
+	OpSub
+	OpMul
+	OpQuo
+	OpRem
+	OpEQ
+	OpNE
+	OpLT
+	OpLE
+	OpGT
+	OpGE
+	OpAnd
+	OpOr
+	OpNot
+	OpNeg
+
+	OpJump        // unconditional jump to A
+	OpJumpIfFalse // pop; jump to A if false
+
+	OpNewStruct // pop len(Strs) values (in Strs order); push struct Value named Str
+	OpGetField  // pop struct; push struct.Fields[Str]
+	OpSetField  // pop value, pop struct; struct.Fields[Str] = value
+
+	OpMakeClosure // push closure over Consts[A].(*FuncProto), capturing upvalues per Strs/UpvalSrc
+	OpCall        // pop callee, pop A args; push proto's results
+	OpCallMethod  // pop A args, pop receiver; dispatch Str method on receiver's type; push results
+	OpGo          // like OpCall but spawns a goroutine and discards results
+	OpDefer       // pop callee, pop A args; schedule for this frame's return
+	OpReturn      // pop A values (already in return order); end the function
+
+	OpMakeChan // push a new channel with buffer size A
+	OpSend     // pop value, pop channel; channel <- value
+	OpRecv     // pop channel; push <-channel
+
+	OpNewSlice // pop A values (in literal order); push a slice Value
+	OpIndex    // pop index, pop slice/string; push the element/byte
+	OpLen      // pop slice/string; push its length
+)
@@ -0,0 +1,24 @@
+package vm
+
+// Program is the compiled output for a package: its top-level
+// functions plus, per struct type, its methods.
+type Program struct {
+	Funcs   map[string]*FuncProto
+	Methods map[string]map[string]*FuncProto // type name -> method name -> proto
+}
+
+// NewProgram returns an empty Program ready for the compiler to fill in.
+func NewProgram() *Program {
+	return &Program{
+		Funcs:   map[string]*FuncProto{},
+		Methods: map[string]map[string]*FuncProto{},
+	}
+}
+
+// AddMethod registers a method proto under its receiver type name.
+func (p *Program) AddMethod(typeName string, proto *FuncProto) {
+	if p.Methods[typeName] == nil {
+		p.Methods[typeName] = map[string]*FuncProto{}
+	}
+	p.Methods[typeName][proto.Name] = proto
+}
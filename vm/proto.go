@@ -0,0 +1,52 @@
+package vm
+
+// Instruction is one compiled bytecode operation. Not every field is
+// meaningful for every Op; see the Op constants for which ones apply.
+type Instruction struct {
+	Op Op
+
+	A int // primary integer operand: const/local/upvalue index, arg count, jump target, buffer size
+
+	Str  string   // primary name operand: field name, method name, native name
+	Strs []string // ordered list operand: struct field names, upvalue names
+}
+
+// UpvalSource says where a closure should find the value to put in one
+// of its upvalue cells: the enclosing function's own locals, or an
+// upvalue the enclosing function itself captured.
+type UpvalSource struct {
+	FromParentLocal bool // true: parent's locals[Index]; false: parent's Upvalues[Index]
+	Index           int
+}
+
+// ReceiverInfo describes a method's receiver.
+type ReceiverInfo struct {
+	Name     string // receiver parameter name, e.g. "c" in (c *Counter)
+	TypeName string // e.g. "Counter"
+	Pointer  bool
+}
+
+// FuncProto is a compiled function or method body, independent of any
+// particular closure over it.
+type FuncProto struct {
+	Name string
+
+	NumParams   int
+	ParamNames  []string
+	Variadic    bool
+	ResultNames []string // empty names allowed; filled in as the body compiles
+	NumResults  int      // result count, known from the signature before the body is compiled
+
+	Receiver *ReceiverInfo
+
+	NumLocals int // total local slots, including params and receiver
+
+	Code   []Instruction
+	Consts []Value
+
+	// UpvalNames are the free variables this function's nested
+	// closures need; UpvalSources says, per name, where to find the
+	// value in the *enclosing* function's frame at OpMakeClosure time.
+	UpvalNames   []string
+	UpvalSources []UpvalSource
+}
@@ -0,0 +1,147 @@
+// Package vm is a small stack-based bytecode interpreter for the Go
+// subset the compiler package knows how to lower: int/string/bool,
+// structs with fields and methods, functions with multiple and named
+// returns, variadics, closures, defer, and goroutines over channels.
+//
+// Struct field promotion through embedding is a compiler-time concept
+// only; the VM itself addresses embedded fields through the embedding
+// field's own name (e.g. emp.Person.Name), not the promoted selector.
+package vm
+
+import "fmt"
+
+// Kind identifies which field of a Value is meaningful.
+type Kind int
+
+// The value kinds the VM can hold on its stack or in a local slot.
+const (
+	KindNil Kind = iota
+	KindInt
+	KindFloat
+	KindBool
+	KindString
+	KindStruct
+	KindClosure
+	KindNative
+	KindChan
+	KindSlice
+	KindProto // an uninstantiated *FuncProto constant, used only inside Consts for OpMakeClosure
+)
+
+// Value is the VM's single dynamically-typed runtime value.
+type Value struct {
+	Kind    Kind
+	Int     int64
+	Float   float64
+	Bool    bool
+	Str     string
+	Struct  *StructValue
+	Closure *Closure
+	Chan    *Channel
+	Proto   *FuncProto
+	Slice   *SliceValue
+}
+
+// SliceValue is always referenced through a pointer, matching Go's
+// slices-share-backing-storage semantics closely enough for the
+// subset's read-mostly, fixed-length usage.
+type SliceValue struct {
+	Elems []Value
+}
+
+// StructValue is always referenced through a pointer so that taking a
+// pointer receiver and mutating a field is visible to every holder of
+// that pointer, matching Go's pointer semantics.
+type StructValue struct {
+	TypeName string
+	Fields   map[string]Value
+}
+
+// Clone returns an independent copy of v, used when a value (not
+// pointer) receiver or a plain assignment must get its own copy of the
+// struct rather than share the original's storage.
+//
+// A nested KindStruct field is cloned recursively too, since Go copies
+// an embedded or named struct field by value, not by reference; a
+// KindSlice field is left sharing its backing SliceValue, since Go
+// copies a slice field by header only, same as the slice package doc
+// already describes for top-level slice values.
+func (v *StructValue) Clone() *StructValue {
+	fields := make(map[string]Value, len(v.Fields))
+	for k, f := range v.Fields {
+		if f.Kind == KindStruct {
+			f.Struct = f.Struct.Clone()
+		}
+		fields[k] = f
+	}
+	return &StructValue{TypeName: v.TypeName, Fields: fields}
+}
+
+// Closure pairs a compiled function with the upvalue cells it captured
+// from enclosing scopes at creation time.
+type Closure struct {
+	Proto    *FuncProto
+	Upvalues []*Value
+}
+
+// Channel wraps a Go channel of Values, giving the VM's goroutines the
+// same blocking send/receive semantics as the source language.
+type Channel struct {
+	C chan Value
+}
+
+// Int returns an int Value.
+func Int(i int64) Value { return Value{Kind: KindInt, Int: i} }
+
+// Float returns a float64 Value.
+func Float(f float64) Value { return Value{Kind: KindFloat, Float: f} }
+
+// Bool returns a bool Value.
+func Bool(b bool) Value { return Value{Kind: KindBool, Bool: b} }
+
+// String returns a string Value.
+func String(s string) Value { return Value{Kind: KindString, Str: s} }
+
+// Nil is the zero Value.
+var Nil = Value{Kind: KindNil}
+
+// Truthy reports whether v should be treated as true in a condition.
+func (v Value) Truthy() bool {
+	switch v.Kind {
+	case KindBool:
+		return v.Bool
+	case KindNil:
+		return false
+	default:
+		return true
+	}
+}
+
+// String renders v for debugging and for native functions like
+// fmt.Println that stringify their arguments.
+func (v Value) String() string {
+	switch v.Kind {
+	case KindNil:
+		return "<nil>"
+	case KindInt:
+		return fmt.Sprintf("%d", v.Int)
+	case KindFloat:
+		return fmt.Sprintf("%g", v.Float)
+	case KindBool:
+		return fmt.Sprintf("%t", v.Bool)
+	case KindString:
+		return v.Str
+	case KindStruct:
+		return fmt.Sprintf("%s%v", v.Struct.TypeName, v.Struct.Fields)
+	case KindClosure:
+		return fmt.Sprintf("<func %s>", v.Closure.Proto.Name)
+	case KindNative:
+		return fmt.Sprintf("<native %s>", v.Str)
+	case KindChan:
+		return "<chan>"
+	case KindSlice:
+		return fmt.Sprintf("%v", v.Slice.Elems)
+	default:
+		return "<?>"
+	}
+}
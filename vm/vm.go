@@ -0,0 +1,463 @@
+package vm
+
+import (
+	"fmt"
+	"sync"
+)
+
+// VM runs compiled Programs. It is safe to share a VM across
+// concurrently running goroutines spawned by OpGo: each call gets its
+// own frame and operand stack.
+type VM struct {
+	Program *Program
+	Natives map[string]NativeFunc
+
+	// wg tracks goroutines spawned via OpGo so Run can optionally be
+	// paired with Wait by an embedder that wants to observe them
+	// finish for tests.
+	wg sync.WaitGroup
+}
+
+// New returns a VM ready to run prog, with the default native
+// bindings registered.
+func New(prog *Program) *VM {
+	return &VM{Program: prog, Natives: DefaultNatives()}
+}
+
+// Wait blocks until every goroutine spawned by OpGo during calls made
+// on this VM has finished.
+func (m *VM) Wait() { m.wg.Wait() }
+
+// Run calls the top-level function named name with args.
+func (m *VM) Run(name string, args ...Value) ([]Value, error) {
+	proto, ok := m.Program.Funcs[name]
+	if !ok {
+		return nil, fmt.Errorf("vm: undefined function %q", name)
+	}
+	return m.call(&Closure{Proto: proto}, args)
+}
+
+// frame is one activation of a FuncProto.
+type frame struct {
+	vm     *VM
+	proto  *FuncProto
+	locals []*Value
+	upvals []*Value
+	stack  []Value
+	defers []deferredCall
+}
+
+type deferredCall struct {
+	callee Value
+	args   []Value
+}
+
+func (m *VM) call(c *Closure, args []Value) (results []Value, err error) {
+	proto := c.Proto
+	f := &frame{vm: m, proto: proto, upvals: c.Upvalues}
+	f.locals = make([]*Value, proto.NumLocals)
+	for i := range f.locals {
+		f.locals[i] = new(Value)
+	}
+
+	// The receiver, if any, always occupies local slot 0, ahead of the
+	// parameters proper; offset every parameter slot by one to account
+	// for it.
+	recvOffset := 0
+	if proto.Receiver != nil {
+		if len(args) > 0 {
+			*f.locals[0] = args[0]
+		}
+		recvOffset = 1
+	}
+	paramArgs := args[recvOffset:]
+
+	fixedParams := proto.NumParams
+	if proto.Variadic {
+		fixedParams--
+	}
+	for i := 0; i < fixedParams && i < len(paramArgs); i++ {
+		*f.locals[recvOffset+i] = paramArgs[i]
+	}
+	if proto.Variadic {
+		// The trailing parameter collects the rest of the call's
+		// arguments into an ordinary slice Value, the same
+		// representation a "[]T{...}" literal produces, so range/len/
+		// index all work on it unchanged.
+		rest := append([]Value(nil), paramArgs[fixedParams:]...)
+		*f.locals[recvOffset+fixedParams] = Value{Kind: KindSlice, Slice: &SliceValue{Elems: rest}}
+	}
+
+	defer func() {
+		for i := len(f.defers) - 1; i >= 0; i-- {
+			d := f.defers[i]
+			_, derr := f.invoke(d.callee, d.args)
+			if derr != nil && err == nil {
+				err = derr
+			}
+		}
+	}()
+
+	results, err = f.run()
+	return results, err
+}
+
+func (f *frame) push(v Value) { f.stack = append(f.stack, v) }
+func (f *frame) pop() Value {
+	v := f.stack[len(f.stack)-1]
+	f.stack = f.stack[:len(f.stack)-1]
+	return v
+}
+func (f *frame) popN(n int) []Value {
+	out := make([]Value, n)
+	copy(out, f.stack[len(f.stack)-n:])
+	f.stack = f.stack[:len(f.stack)-n]
+	return out
+}
+
+func (f *frame) run() ([]Value, error) {
+	pc := 0
+	code := f.proto.Code
+	for pc < len(code) {
+		ins := code[pc]
+		switch ins.Op {
+		case OpConst:
+			f.push(f.proto.Consts[ins.A])
+		case OpLoadLocal:
+			f.push(*f.locals[ins.A])
+		case OpStoreLocal:
+			*f.locals[ins.A] = f.pop()
+		case OpLoadUpval:
+			f.push(*f.upvals[ins.A])
+		case OpStoreUpval:
+			*f.upvals[ins.A] = f.pop()
+		case OpPop:
+			f.pop()
+		case OpDup:
+			f.push(f.stack[len(f.stack)-1])
+
+		case OpAdd, OpSub, OpMul, OpQuo, OpRem:
+			if err := f.binArith(ins.Op); err != nil {
+				return nil, err
+			}
+		case OpEQ, OpNE, OpLT, OpLE, OpGT, OpGE:
+			if err := f.compare(ins.Op); err != nil {
+				return nil, err
+			}
+		case OpAnd:
+			b, a := f.pop(), f.pop()
+			f.push(Bool(a.Truthy() && b.Truthy()))
+		case OpOr:
+			b, a := f.pop(), f.pop()
+			f.push(Bool(a.Truthy() || b.Truthy()))
+		case OpNot:
+			a := f.pop()
+			f.push(Bool(!a.Truthy()))
+		case OpNeg:
+			a := f.pop()
+			f.push(Int(-a.Int))
+
+		case OpJump:
+			pc = ins.A
+			continue
+		case OpJumpIfFalse:
+			if !f.pop().Truthy() {
+				pc = ins.A
+				continue
+			}
+
+		case OpNewStruct:
+			fields := make(map[string]Value, len(ins.Strs))
+			vals := f.popN(len(ins.Strs))
+			for i, name := range ins.Strs {
+				fields[name] = vals[i]
+			}
+			f.push(Value{Kind: KindStruct, Struct: &StructValue{TypeName: ins.Str, Fields: fields}})
+		case OpGetField:
+			s := f.pop()
+			if s.Kind != KindStruct {
+				return nil, fmt.Errorf("vm: GetField %s on non-struct", ins.Str)
+			}
+			f.push(s.Struct.Fields[ins.Str])
+		case OpSetField:
+			val := f.pop()
+			s := f.pop()
+			if s.Kind != KindStruct {
+				return nil, fmt.Errorf("vm: SetField %s on non-struct", ins.Str)
+			}
+			s.Struct.Fields[ins.Str] = val
+
+		case OpMakeClosure:
+			inner := f.proto.Consts[ins.A].Proto
+			closure := &Closure{Proto: inner, Upvalues: make([]*Value, len(inner.UpvalSources))}
+			for i, src := range inner.UpvalSources {
+				if src.FromParentLocal {
+					closure.Upvalues[i] = f.locals[src.Index]
+				} else {
+					closure.Upvalues[i] = f.upvals[src.Index]
+				}
+			}
+			f.push(Value{Kind: KindClosure, Closure: closure})
+
+		case OpCall:
+			args := f.popN(ins.A)
+			callee := f.pop()
+			res, err := f.invoke(callee, args)
+			if err != nil {
+				return nil, err
+			}
+			f.stack = append(f.stack, res...)
+
+		case OpCallMethod:
+			args := f.popN(ins.A)
+			recv := f.pop()
+			res, err := f.invokeMethod(recv, ins.Str, args)
+			if err != nil {
+				return nil, err
+			}
+			f.stack = append(f.stack, res...)
+
+		case OpGo:
+			args := f.popN(ins.A)
+			callee := f.pop()
+			f.vm.wg.Add(1)
+			go func() {
+				defer f.vm.wg.Done()
+				f.invoke(callee, args)
+			}()
+
+		case OpDefer:
+			args := f.popN(ins.A)
+			callee := f.pop()
+			f.defers = append(f.defers, deferredCall{callee: callee, args: args})
+
+		case OpReturn:
+			return f.popN(ins.A), nil
+
+		case OpMakeChan:
+			buf := ins.A
+			f.push(Value{Kind: KindChan, Chan: &Channel{C: make(chan Value, buf)}})
+		case OpSend:
+			val := f.pop()
+			ch := f.pop()
+			ch.Chan.C <- val
+		case OpRecv:
+			ch := f.pop()
+			f.push(<-ch.Chan.C)
+
+		case OpNewSlice:
+			elems := f.popN(ins.A)
+			f.push(Value{Kind: KindSlice, Slice: &SliceValue{Elems: elems}})
+		case OpIndex:
+			idx := f.pop()
+			s := f.pop()
+			switch s.Kind {
+			case KindSlice:
+				if idx.Int < 0 || int(idx.Int) >= len(s.Slice.Elems) {
+					return nil, fmt.Errorf("vm: index %d out of range [0:%d]", idx.Int, len(s.Slice.Elems))
+				}
+				f.push(s.Slice.Elems[idx.Int])
+			case KindString:
+				if idx.Int < 0 || int(idx.Int) >= len(s.Str) {
+					return nil, fmt.Errorf("vm: index %d out of range [0:%d]", idx.Int, len(s.Str))
+				}
+				f.push(Int(int64(s.Str[idx.Int])))
+			default:
+				return nil, fmt.Errorf("vm: cannot index value of kind %d", s.Kind)
+			}
+		case OpLen:
+			s := f.pop()
+			switch s.Kind {
+			case KindSlice:
+				f.push(Int(int64(len(s.Slice.Elems))))
+			case KindString:
+				f.push(Int(int64(len(s.Str))))
+			default:
+				return nil, fmt.Errorf("vm: cannot take len of value of kind %d", s.Kind)
+			}
+
+		default:
+			return nil, fmt.Errorf("vm: unhandled opcode %d", ins.Op)
+		}
+		pc++
+	}
+	return nil, nil
+}
+
+func (f *frame) invoke(callee Value, args []Value) ([]Value, error) {
+	return f.vm.Call(callee, args...)
+}
+
+func (f *frame) invokeMethod(recv Value, method string, args []Value) ([]Value, error) {
+	return f.vm.CallMethod(recv, method, args...)
+}
+
+// Call invokes a closure or native Value directly, the same way the
+// VM itself does for OpCall. It's the entry point for calling a value
+// an embedder received back from a prior Run (a closure returned from
+// the compiled code, for example).
+func (m *VM) Call(callee Value, args ...Value) ([]Value, error) {
+	switch callee.Kind {
+	case KindClosure:
+		return m.call(callee.Closure, args)
+	case KindNative:
+		fn, ok := m.Natives[callee.Str]
+		if !ok {
+			return nil, fmt.Errorf("vm: undefined native %q", callee.Str)
+		}
+		return fn(args)
+	default:
+		return nil, fmt.Errorf("vm: value of kind %d is not callable", callee.Kind)
+	}
+}
+
+// CallMethod dispatches method on recv, cloning recv's struct first if
+// the method has a value (non-pointer) receiver, matching Go's method
+// call semantics.
+func (m *VM) CallMethod(recv Value, method string, args ...Value) ([]Value, error) {
+	if recv.Kind != KindStruct {
+		return nil, fmt.Errorf("vm: cannot call method %s on non-struct", method)
+	}
+	proto, ok := m.Program.Methods[recv.Struct.TypeName][method]
+	if !ok {
+		return nil, fmt.Errorf("vm: %s has no method %s", recv.Struct.TypeName, method)
+	}
+
+	recvVal := recv
+	if !proto.Receiver.Pointer {
+		recvVal = Value{Kind: KindStruct, Struct: recv.Struct.Clone()}
+	}
+	callArgs := append([]Value{recvVal}, args...)
+	return m.call(&Closure{Proto: proto}, callArgs)
+}
+
+func (f *frame) binArith(op Op) error {
+	b, a := f.pop(), f.pop()
+	if a.Kind == KindString || b.Kind == KindString {
+		if op != OpAdd {
+			return fmt.Errorf("vm: operator %d not defined on strings", op)
+		}
+		f.push(String(a.Str + b.Str))
+		return nil
+	}
+	if a.Kind == KindFloat || b.Kind == KindFloat {
+		af, bf := asFloat(a), asFloat(b)
+		switch op {
+		case OpAdd:
+			f.push(Float(af + bf))
+		case OpSub:
+			f.push(Float(af - bf))
+		case OpMul:
+			f.push(Float(af * bf))
+		case OpQuo:
+			if bf == 0 {
+				return fmt.Errorf("vm: division by zero")
+			}
+			f.push(Float(af / bf))
+		default:
+			return fmt.Errorf("vm: operator %d not defined on floats", op)
+		}
+		return nil
+	}
+	switch op {
+	case OpAdd:
+		f.push(Int(a.Int + b.Int))
+	case OpSub:
+		f.push(Int(a.Int - b.Int))
+	case OpMul:
+		f.push(Int(a.Int * b.Int))
+	case OpQuo:
+		if b.Int == 0 {
+			return fmt.Errorf("vm: division by zero")
+		}
+		f.push(Int(a.Int / b.Int))
+	case OpRem:
+		if b.Int == 0 {
+			return fmt.Errorf("vm: division by zero")
+		}
+		f.push(Int(a.Int % b.Int))
+	}
+	return nil
+}
+
+func (f *frame) compare(op Op) error {
+	b, a := f.pop(), f.pop()
+	switch op {
+	case OpEQ:
+		f.push(Bool(valuesEqual(a, b)))
+		return nil
+	case OpNE:
+		f.push(Bool(!valuesEqual(a, b)))
+		return nil
+	}
+	if a.Kind == KindString && b.Kind == KindString {
+		switch op {
+		case OpLT:
+			f.push(Bool(a.Str < b.Str))
+		case OpLE:
+			f.push(Bool(a.Str <= b.Str))
+		case OpGT:
+			f.push(Bool(a.Str > b.Str))
+		case OpGE:
+			f.push(Bool(a.Str >= b.Str))
+		}
+		return nil
+	}
+	if a.Kind == KindFloat || b.Kind == KindFloat {
+		af, bf := asFloat(a), asFloat(b)
+		switch op {
+		case OpLT:
+			f.push(Bool(af < bf))
+		case OpLE:
+			f.push(Bool(af <= bf))
+		case OpGT:
+			f.push(Bool(af > bf))
+		case OpGE:
+			f.push(Bool(af >= bf))
+		}
+		return nil
+	}
+	switch op {
+	case OpLT:
+		f.push(Bool(a.Int < b.Int))
+	case OpLE:
+		f.push(Bool(a.Int <= b.Int))
+	case OpGT:
+		f.push(Bool(a.Int > b.Int))
+	case OpGE:
+		f.push(Bool(a.Int >= b.Int))
+	}
+	return nil
+}
+
+func asFloat(v Value) float64 {
+	if v.Kind == KindInt {
+		return float64(v.Int)
+	}
+	return v.Float
+}
+
+func valuesEqual(a, b Value) bool {
+	if a.Kind == KindNil || b.Kind == KindNil {
+		return a.Kind == KindNil && b.Kind == KindNil
+	}
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case KindInt:
+		return a.Int == b.Int
+	case KindFloat:
+		return a.Float == b.Float
+	case KindBool:
+		return a.Bool == b.Bool
+	case KindString:
+		return a.Str == b.Str
+	case KindNil:
+		return true
+	case KindStruct:
+		return a.Struct == b.Struct
+	default:
+		return false
+	}
+}